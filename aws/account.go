@@ -0,0 +1,28 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// GetAccountId returns the id of the account the credentials in cfg belong to.
+func GetAccountId(cfg *aws.Config) (string, error) {
+	identity, err := sts.NewFromConfig(*cfg).GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", err
+	}
+	return *identity.Account, nil
+}
+
+// GetAccountName returns the account alias, or the account id when no alias is set
+// or the caller is not allowed to list it.
+func GetAccountName(cfg *aws.Config) (string, error) {
+	output, err := iam.NewFromConfig(*cfg).ListAccountAliases(context.Background(), &iam.ListAccountAliasesInput{})
+	if err == nil && len(output.AccountAliases) > 0 {
+		return output.AccountAliases[0], nil
+	}
+	return GetAccountId(cfg)
+}
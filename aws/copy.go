@@ -0,0 +1,286 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// layer availability can be checked for at most 100 digests at a time
+const layerAvailabilityBatchSize int = 100
+
+// MissingBlobs returns the digests of the blobs that the repository does not have.
+func (s *EcrService) MissingBlobs(repository string, digests []string) ([]string, error) {
+	var missing []string
+	for start := 0; start < len(digests); start += layerAvailabilityBatchSize {
+		batch := digests[start:min(start+layerAvailabilityBatchSize, len(digests))]
+		input := ecr.BatchCheckLayerAvailabilityInput{RepositoryName: &repository, LayerDigests: batch}
+		output, err := s.Client.BatchCheckLayerAvailability(context.Background(), &input)
+		if err != nil {
+			return nil, err
+		}
+		available := map[string]bool{}
+		for _, layer := range output.Layers {
+			available[*layer.LayerDigest] = layer.LayerAvailability == types.LayerAvailabilityAvailable
+		}
+		for _, digest := range batch {
+			if !available[digest] {
+				missing = append(missing, digest)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// DownloadBlob downloads the blob through its pre-signed URL, the caller closes the body.
+func (s *EcrService) DownloadBlob(ctx context.Context, repository string, digest string) (io.ReadCloser, error) {
+	layer, err := s.Client.GetDownloadUrlForLayer(ctx, &ecr.GetDownloadUrlForLayerInput{RepositoryName: &repository, LayerDigest: &digest})
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, *layer.DownloadUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("failed to download %s: %s", digest, response.Status)
+	}
+	return response.Body, nil
+}
+
+// UploadBlob uploads the blob in parts of the size ECR asks for, calling progress with the size of every part.
+func (s *EcrService) UploadBlob(ctx context.Context, repository string, digest string, blob io.Reader, progress func(int64)) error {
+	upload, err := s.Client.InitiateLayerUpload(ctx, &ecr.InitiateLayerUploadInput{RepositoryName: &repository})
+	if err != nil {
+		return err
+	}
+
+	part := make([]byte, *upload.PartSize)
+	var firstByte int64
+	for {
+		n, err := io.ReadFull(blob, part)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+		lastByte := firstByte + int64(n) - 1
+		input := ecr.UploadLayerPartInput{RepositoryName: &repository, UploadId: upload.UploadId, PartFirstByte: &firstByte, PartLastByte: &lastByte, LayerPartBlob: part[:n]}
+		if _, err := s.Client.UploadLayerPart(ctx, &input); err != nil {
+			return err
+		}
+		progress(int64(n))
+		firstByte = lastByte + 1
+		if n < len(part) {
+			break
+		}
+	}
+
+	_, err = s.Client.CompleteLayerUpload(ctx, &ecr.CompleteLayerUploadInput{RepositoryName: &repository, UploadId: upload.UploadId, LayerDigests: []string{digest}})
+	var exists *types.LayerAlreadyExistsException
+	if errors.As(err, &exists) {
+		return nil
+	}
+	return err
+}
+
+// PutManifest puts the manifest with its media type, tagged unless tag is empty. Putting a manifest
+// the repository already has under the tag is not an error.
+func (s *EcrService) PutManifest(repository string, tag string, digest string, manifest string, mediaType string) error {
+	input := ecr.PutImageInput{RepositoryName: &repository, ImageManifest: &manifest, ImageDigest: &digest}
+	if mediaType != "" {
+		input.ImageManifestMediaType = &mediaType
+	}
+	if tag != "" {
+		input.ImageTag = &tag
+	}
+	_, err := s.Client.PutImage(context.Background(), &input)
+	var exists *types.ImageAlreadyExistsException
+	if errors.As(err, &exists) {
+		return nil
+	}
+	return err
+}
+
+// CopyProgress is the state of an image copy
+type CopyProgress struct {
+	Blobs     int
+	BlobsDone int
+	Bytes     int64
+	BytesDone int64
+}
+
+// ImageCopy copies an image with its layers to a repository of any account and region
+type ImageCopy struct {
+	Source                *EcrService
+	SourceRepository      string
+	SourceImage           types.ImageIdentifier
+	Destination           *EcrService
+	DestinationRepository string
+	DestinationTag        string
+	// Parallel is the number of blobs transferred at the same time
+	Parallel int
+	// Progress is called every time a part of a blob is transferred
+	Progress func(CopyProgress)
+}
+
+// CopyResult tells what an image copy transferred
+type CopyResult struct {
+	Digest string
+	// Blobs is the number of blobs of the image, Copied the ones that were missing and transferred
+	Blobs  int
+	Copied int
+	Bytes  int64
+}
+
+type imageManifest struct {
+	digest    string
+	body      string
+	mediaType string
+}
+
+// Run copies the blobs the destination is missing and then puts the manifests, the platform
+// manifests of a multi-platform image before its index.
+func (c *ImageCopy) Run(ctx context.Context) (*CopyResult, error) {
+	image, err := c.Source.GetImage(c.SourceRepository, c.SourceImage)
+	if err != nil {
+		return nil, err
+	}
+	top := imageManifest{digest: *image.ImageId.ImageDigest, body: *image.ImageManifest, mediaType: aws.ToString(image.ImageManifestMediaType)}
+
+	var children []imageManifest
+	sizes := map[string]int64{}
+	var digests []string
+	addBlobs := func(body string) error {
+		var manifest Manifest
+		if err := json.Unmarshal([]byte(body), &manifest); err != nil {
+			return err
+		}
+		for _, blob := range append([]Blob{manifest.Config}, manifest.Layers...) {
+			if _, ok := sizes[blob.Digest]; !ok && blob.Digest != "" {
+				sizes[blob.Digest] = blob.Size
+				digests = append(digests, blob.Digest)
+			}
+		}
+		return nil
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal([]byte(top.body), &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.IsIndex() {
+		for _, platform := range manifest.Manifests {
+			child, err := c.Source.GetImage(c.SourceRepository, types.ImageIdentifier{ImageDigest: &platform.Digest})
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, imageManifest{digest: platform.Digest, body: *child.ImageManifest, mediaType: aws.ToString(child.ImageManifestMediaType)})
+			if err := addBlobs(*child.ImageManifest); err != nil {
+				return nil, err
+			}
+		}
+	} else if err := addBlobs(top.body); err != nil {
+		return nil, err
+	}
+
+	missing, err := c.Destination.MissingBlobs(c.DestinationRepository, digests)
+	if err != nil {
+		return nil, err
+	}
+	result := &CopyResult{Digest: top.digest, Blobs: len(digests), Copied: len(missing)}
+	for _, digest := range missing {
+		result.Bytes += sizes[digest]
+	}
+	if err := c.transfer(ctx, missing, result.Bytes); err != nil {
+		return nil, err
+	}
+
+	for _, child := range children {
+		if err := c.Destination.PutManifest(c.DestinationRepository, "", child.digest, child.body, child.mediaType); err != nil {
+			return nil, fmt.Errorf("failed to put the manifest %s: %w", child.digest, err)
+		}
+	}
+	if err := c.Destination.PutManifest(c.DestinationRepository, c.DestinationTag, top.digest, top.body, top.mediaType); err != nil {
+		return nil, fmt.Errorf("failed to put the manifest %s: %w", top.digest, err)
+	}
+	return result, nil
+}
+
+// transfer copies the blobs with c.Parallel workers, stopping at the first error.
+func (c *ImageCopy) transfer(ctx context.Context, digests []string, bytes int64) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		progress = CopyProgress{Blobs: len(digests), Bytes: bytes}
+	)
+	report := func(blobs int, bytes int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress.BlobsDone += blobs
+		progress.BytesDone += bytes
+		if c.Progress != nil {
+			c.Progress(progress)
+		}
+	}
+
+	queue := make(chan string)
+	for range max(c.Parallel, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for digest := range queue {
+				err := c.copyBlob(ctx, digest, func(n int64) { report(0, n) })
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("failed to copy %s: %w", digest, err)
+					}
+					mu.Unlock()
+					cancel()
+					continue
+				}
+				report(1, 0)
+			}
+		}()
+	}
+	for _, digest := range digests {
+		select {
+		case queue <- digest:
+		case <-ctx.Done():
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return firstErr
+}
+
+func (c *ImageCopy) copyBlob(ctx context.Context, digest string, progress func(int64)) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	blob, err := c.Source.DownloadBlob(ctx, c.SourceRepository, digest)
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+	return c.Destination.UploadBlob(ctx, c.DestinationRepository, digest, blob, progress)
+}
@@ -2,6 +2,9 @@ package aws
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/ecr"
 	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
@@ -11,28 +14,153 @@ type EcrService struct {
 	Client *ecr.Client
 }
 
-func (s *EcrService) GetImageManifest(repository string, imageTag string) (string, error) {
-	image, err := s.GetImage(repository, imageTag)
+// GetImage returns the image with its manifest in the media type it was pushed with, e.g. an OCI
+// image index for a multi-platform image.
+func (s *EcrService) GetImage(repository string, imageId types.ImageIdentifier) (*types.Image, error) {
+	input := ecr.BatchGetImageInput{RepositoryName: &repository, ImageIds: []types.ImageIdentifier{imageId}, AcceptedMediaTypes: ManifestMediaTypes}
+	output, err := s.Client.BatchGetImage(context.Background(), &input)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if len(output.Images) == 0 {
+		reference := imageReference(imageId)
+		if len(output.Failures) > 0 && output.Failures[0].FailureReason != nil {
+			return nil, fmt.Errorf("%s%s: %s", repository, reference, *output.Failures[0].FailureReason)
+		}
+		return nil, fmt.Errorf("%s%s: image not found", repository, reference)
 	}
-	return *image.ImageManifest, nil
+	return &output.Images[0], nil
 }
 
-func (s *EcrService) GetImage(repository string, imageTag string) (*types.Image, error) {
-	input := ecr.BatchGetImageInput{ImageIds: []types.ImageIdentifier{{ImageTag: &imageTag}}, RepositoryName: &repository}
-	output, err := s.Client.BatchGetImage(context.Background(), &input)
+// imageReference returns :tag or @digest of the image.
+func imageReference(imageId types.ImageIdentifier) string {
+	if imageId.ImageDigest != nil {
+		return "@" + *imageId.ImageDigest
+	}
+	if imageId.ImageTag != nil {
+		return ":" + *imageId.ImageTag
+	}
+	return ""
+}
+
+// GetRepositoryUri returns the URI of the repository, e.g. 123456789012.dkr.ecr.eu-west-1.amazonaws.com/api
+func (s *EcrService) GetRepositoryUri(repository string) (string, error) {
+	input := ecr.DescribeRepositoriesInput{RepositoryNames: []string{repository}}
+	output, err := s.Client.DescribeRepositories(context.Background(), &input)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	return &output.Images[0], err
+	return *output.Repositories[0].RepositoryUri, nil
 }
 
-func (s *EcrService) PutImage(repository string, imageTag string, imageManifest string) (*types.Image, error) {
+// PutImage tags the manifest, the media type must be the one the manifest was pushed with.
+func (s *EcrService) PutImage(repository string, imageTag string, imageManifest string, mediaType string) (*types.Image, error) {
 	input := ecr.PutImageInput{RepositoryName: &repository, ImageTag: &imageTag, ImageManifest: &imageManifest}
+	if mediaType != "" {
+		input.ImageManifestMediaType = &mediaType
+	}
 	output, err := s.Client.PutImage(context.Background(), &input)
 	if err != nil {
 		return nil, err
 	}
 	return output.Image, nil
 }
+
+// EcrImage is a parsed reference to an ECR image, <account>.dkr.ecr.<region>.amazonaws.com/<repository>[:tag][@digest]
+type EcrImage struct {
+	Account    string
+	Region     string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseEcrImage parses the image reference, ok is false when the image is not in ECR.
+func ParseEcrImage(image string) (*EcrImage, bool) {
+	registry, name, ok := strings.Cut(image, "/")
+	if !ok {
+		return nil, false
+	}
+	// <account>.dkr.ecr.<region>.amazonaws.com
+	parts := strings.Split(registry, ".")
+	if len(parts) < 6 || parts[1] != "dkr" || !strings.HasPrefix(parts[2], "ecr") {
+		return nil, false
+	}
+
+	ecrImage := &EcrImage{Account: parts[0], Region: parts[3]}
+	name, ecrImage.Digest, _ = strings.Cut(name, "@")
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name, ecrImage.Tag = name[:i], name[i+1:]
+	}
+	if ecrImage.Tag == "" && ecrImage.Digest == "" {
+		ecrImage.Tag = "latest"
+	}
+	ecrImage.Repository = name
+	return ecrImage, true
+}
+
+// ImageId identifies the image by its digest, or its tag when it has no digest.
+func (i *EcrImage) ImageId() types.ImageIdentifier {
+	if i.Digest != "" {
+		return types.ImageIdentifier{ImageDigest: &i.Digest}
+	}
+	return types.ImageIdentifier{ImageTag: &i.Tag}
+}
+
+// Reference returns the tag or digest of the image.
+func (i *EcrImage) Reference() string {
+	if i.Digest != "" {
+		return i.Digest
+	}
+	return i.Tag
+}
+
+func (i *EcrImage) RepositoryArn() string {
+	return fmt.Sprintf("arn:aws:ecr:%s:%s:repository/%s", i.Region, i.Account, i.Repository)
+}
+
+func (s *EcrService) ListRepositories() ([]types.Repository, error) {
+	var repositories []types.Repository
+
+	paginator := ecr.NewDescribeRepositoriesPaginator(s.Client, &ecr.DescribeRepositoriesInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		repositories = append(repositories, output.Repositories...)
+	}
+	return repositories, nil
+}
+
+func (s *EcrService) DescribeRepository(registryId string, repository string) (*types.Repository, error) {
+	input := ecr.DescribeRepositoriesInput{RegistryId: &registryId, RepositoryNames: []string{repository}}
+	output, err := s.Client.DescribeRepositories(context.Background(), &input)
+	if err != nil {
+		return nil, err
+	}
+	return &output.Repositories[0], nil
+}
+
+func (s *EcrService) DescribeImage(registryId string, repository string, imageId types.ImageIdentifier) (*types.ImageDetail, error) {
+	input := ecr.DescribeImagesInput{RegistryId: &registryId, RepositoryName: &repository, ImageIds: []types.ImageIdentifier{imageId}}
+	output, err := s.Client.DescribeImages(context.Background(), &input)
+	if err != nil {
+		return nil, err
+	}
+	return &output.ImageDetails[0], nil
+}
+
+// GetRepositoryPolicy returns the policy of the repository, "" when it has none.
+func (s *EcrService) GetRepositoryPolicy(registryId string, repository string) (string, error) {
+	input := ecr.GetRepositoryPolicyInput{RegistryId: &registryId, RepositoryName: &repository}
+	output, err := s.Client.GetRepositoryPolicy(context.Background(), &input)
+	var notFound *types.RepositoryPolicyNotFoundException
+	if errors.As(err, &notFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return *output.PolicyText, nil
+}
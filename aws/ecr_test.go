@@ -0,0 +1,512 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+func TestParseEcrImage(t *testing.T) {
+	image, ok := ParseEcrImage("123456789012.dkr.ecr.eu-west-1.amazonaws.com/team/api:1.0")
+	if !ok || image.Account != "123456789012" || image.Region != "eu-west-1" || image.Repository != "team/api" || image.Tag != "1.0" {
+		t.Fatalf("Unexpected image %+v", image)
+	}
+
+	image, ok = ParseEcrImage("123456789012.dkr.ecr.eu-west-1.amazonaws.com/api@sha256:abc")
+	if !ok || image.Repository != "api" || image.Digest != "sha256:abc" || image.Reference() != "sha256:abc" {
+		t.Fatalf("Unexpected image %+v", image)
+	}
+
+	if image, _ := ParseEcrImage("123456789012.dkr.ecr.eu-west-1.amazonaws.com/api"); image.Tag != "latest" {
+		t.Fatalf("Expected the latest tag, got %s", image.Tag)
+	}
+	if _, ok := ParseEcrImage("docker.io/library/nginx:1.25"); ok {
+		t.Fatalf("Expected docker.io not to be an ECR image")
+	}
+}
+
+func TestPolicyAllows(t *testing.T) {
+	policy := `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {"Effect": "Allow", "Principal": {"AWS": ["arn:aws:iam::111111111111:root"]}, "Action": ["ecr:BatchGetImage", "ecr:GetDownloadUrlForLayer"]},
+    {"Effect": "Allow", "Principal": {"AWS": "arn:aws:iam::222222222222:role/nodes"}, "Action": "ecr:*"},
+    {"Effect": "Deny", "Principal": "*", "Action": "ecr:BatchGetImage", "Sid": "unused"}
+  ]
+}`
+	if allowed, err := PolicyAllows(policy, "ecr:BatchGetImage", "111111111111", ""); err != nil || allowed {
+		t.Fatalf("Expected the deny statement to win, got %v (%v)", allowed, err)
+	}
+
+	policy = `{"Statement": {"Effect": "Allow", "Principal": {"AWS": "arn:aws:iam::222222222222:role/nodes"}, "Action": "ecr:*"}}`
+	if allowed, _ := PolicyAllows(policy, "ecr:BatchGetImage", "222222222222", "arn:aws:iam::222222222222:role/nodes"); !allowed {
+		t.Fatalf("Expected the role to be allowed")
+	}
+	if allowed, _ := PolicyAllows(policy, "ecr:BatchGetImage", "333333333333", "arn:aws:iam::333333333333:role/nodes"); allowed {
+		t.Fatalf("Expected another account not to be allowed")
+	}
+}
+
+// fakeEcrError is answered by the fake ECR API as an error with the code
+type fakeEcrError string
+
+// newFakeEcr starts an ECR API that answers every operation with the response of handle,
+// the operation is the name of the action, e.g. PutImage.
+func newFakeEcr(t *testing.T, handle func(operation string, input map[string]any) any) (*EcrService, *httptest.Server) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input map[string]any
+		json.NewDecoder(r.Body).Decode(&input)
+		_, operation, _ := strings.Cut(r.Header.Get("X-Amz-Target"), ".")
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		response := handle(operation, input)
+		if code, ok := response.(fakeEcrError); ok {
+			w.WriteHeader(http.StatusBadRequest)
+			response = map[string]any{"__type": string(code), "message": "fake " + string(code)}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	client := ecr.New(ecr.Options{
+		Region:       "eu-west-1",
+		BaseEndpoint: &server.URL,
+		Credentials:  credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "SECRET", ""),
+	})
+	return &EcrService{Client: client}, server
+}
+
+// newImage returns an image with the tags pushed at the time
+func newImage(digest string, pushed time.Time, tags ...string) types.ImageDetail {
+	return types.ImageDetail{ImageDigest: aws.String(digest), ImageTags: tags, ImagePushedAt: aws.Time(pushed)}
+}
+
+func TestImageCopy(t *testing.T) {
+	layer := []byte("0123456789")
+	manifest := `{"mediaType":"` + DockerManifest + `","config":{"digest":"sha256:config","size":2},"layers":[{"digest":"sha256:layer","size":10}]}`
+
+	var blobs *httptest.Server
+	source, _ := newFakeEcr(t, func(operation string, input map[string]any) any {
+		switch operation {
+		case "BatchGetImage":
+			return map[string]any{"images": []any{map[string]any{
+				"imageId":                map[string]any{"imageDigest": "sha256:image", "imageTag": "1.0"},
+				"imageManifest":          manifest,
+				"imageManifestMediaType": DockerManifest,
+			}}}
+		case "GetDownloadUrlForLayer":
+			return map[string]any{"downloadUrl": blobs.URL + "/" + input["layerDigest"].(string)}
+		}
+		return map[string]any{}
+	})
+	blobs = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write(layer) }))
+	defer blobs.Close()
+
+	var uploaded []byte
+	var put map[string]any
+	destination, _ := newFakeEcr(t, func(operation string, input map[string]any) any {
+		switch operation {
+		case "BatchCheckLayerAvailability":
+			return map[string]any{"layers": []any{
+				map[string]any{"layerDigest": "sha256:config", "layerAvailability": "AVAILABLE"},
+				map[string]any{"layerDigest": "sha256:layer", "layerAvailability": "UNAVAILABLE"},
+			}}
+		case "InitiateLayerUpload":
+			return map[string]any{"partSize": 4, "uploadId": "upload"}
+		case "UploadLayerPart":
+			part, _ := base64.StdEncoding.DecodeString(input["layerPartBlob"].(string))
+			if int(input["partFirstByte"].(float64)) != len(uploaded) {
+				t.Errorf("Expected the part to start at %d, got %v", len(uploaded), input["partFirstByte"])
+			}
+			uploaded = append(uploaded, part...)
+		case "PutImage":
+			put = input
+		}
+		return map[string]any{}
+	})
+
+	imageCopy := ImageCopy{
+		Source: source, SourceRepository: "api", SourceImage: types.ImageIdentifier{ImageTag: aws.String("1.0")},
+		Destination: destination, DestinationRepository: "api", DestinationTag: "1.0", Parallel: 2,
+	}
+	result, err := imageCopy.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to copy the image: %v", err)
+	}
+	if result.Blobs != 2 || result.Copied != 1 || result.Bytes != 10 {
+		t.Fatalf("Expected only the layer to be copied, got %+v", result)
+	}
+	if string(uploaded) != string(layer) {
+		t.Fatalf("Expected the layer to be uploaded in parts, got %q", uploaded)
+	}
+	if put["imageTag"] != "1.0" || put["imageDigest"] != "sha256:image" || put["imageManifest"] != manifest {
+		t.Fatalf("Expected the manifest to be put with the tag, got %v", put)
+	}
+}
+
+func TestRetagIndex(t *testing.T) {
+	index := `{"mediaType":"` + OciImageIndex + `","manifests":[{"digest":"sha256:amd64"},{"digest":"sha256:arm64"}]}`
+	var put map[string]any
+	_ecr, _ := newFakeEcr(t, func(operation string, input map[string]any) any {
+		switch operation {
+		case "BatchGetImage":
+			// only the amd64 platform manifest was pushed
+			if len(input["imageIds"].([]any)) == 2 {
+				return map[string]any{"images": []any{map[string]any{"imageId": map[string]any{"imageDigest": "sha256:amd64"}}}}
+			}
+			return map[string]any{"images": []any{map[string]any{
+				"imageId":                map[string]any{"imageDigest": "sha256:index", "imageTag": "1.0"},
+				"imageManifest":          index,
+				"imageManifestMediaType": OciImageIndex,
+			}}}
+		case "PutImage":
+			put = input
+		}
+		return map[string]any{}
+	})
+
+	image, err := _ecr.GetImage("api", types.ImageIdentifier{ImageTag: aws.String("1.0")})
+	if err != nil || aws.ToString(image.ImageManifestMediaType) != OciImageIndex {
+		t.Fatalf("Expected the image index, got %v (%v)", image, err)
+	}
+	missing, err := _ecr.MissingChildManifests("api", *image.ImageManifest)
+	if err != nil || len(missing) != 1 || missing[0] != "sha256:arm64" {
+		t.Fatalf("Expected the arm64 manifest to be missing, got %v (%v)", missing, err)
+	}
+	if _, err := _ecr.PutImage("api", "stable", *image.ImageManifest, *image.ImageManifestMediaType); err != nil {
+		t.Fatalf("Failed to put the image: %v", err)
+	}
+	if put["imageManifestMediaType"] != OciImageIndex {
+		t.Fatalf("Expected the media type of the index to be kept, got %v", put["imageManifestMediaType"])
+	}
+
+	// BatchGetImage accepts at most 100 image IDs
+	var children []string
+	for i := range 250 {
+		children = append(children, fmt.Sprintf(`{"digest":"sha256:%d"}`, i))
+	}
+	large := `{"mediaType":"` + OciImageIndex + `","manifests":[` + strings.Join(children, ",") + `]}`
+	var batches []int
+	_ecr, _ = newFakeEcr(t, func(operation string, input map[string]any) any {
+		imageIds := input["imageIds"].([]any)
+		batches = append(batches, len(imageIds))
+		var images []any
+		for _, imageId := range imageIds {
+			if digest := imageId.(map[string]any)["imageDigest"]; digest != "sha256:249" {
+				images = append(images, map[string]any{"imageId": map[string]any{"imageDigest": digest}})
+			}
+		}
+		return map[string]any{"images": images}
+	})
+	missing, err = _ecr.MissingChildManifests("api", large)
+	if err != nil || !slices.Equal(missing, []string{"sha256:249"}) || !slices.Equal(batches, []int{100, 100, 50}) {
+		t.Fatalf("Expected sha256:249 to be missing in batches of 100, got %v in %v (%v)", missing, batches, err)
+	}
+}
+
+func TestRetag(t *testing.T) {
+	tags := map[string]string{"1.0": "sha256:new", "stable": "sha256:old", "current": "sha256:new"}
+	immutable := false
+	_ecr, _ := newFakeEcr(t, func(operation string, input map[string]any) any {
+		tag, _ := input["imageTag"].(string)
+		if imageIds, ok := input["imageIds"].([]any); ok {
+			tag, _ = imageIds[0].(map[string]any)["imageTag"].(string)
+		}
+		switch operation {
+		case "BatchGetImage":
+			return map[string]any{"images": []any{map[string]any{"imageId": map[string]any{"imageDigest": tags[tag]}, "imageManifest": "{}"}}}
+		case "DescribeImages":
+			if tags[tag] == "" {
+				return fakeEcrError("ImageNotFoundException")
+			}
+			return map[string]any{"imageDetails": []any{map[string]any{"imageDigest": tags[tag]}}}
+		case "PutImage":
+			if immutable && tags[tag] != "" {
+				return fakeEcrError("ImageTagAlreadyExistsException")
+			}
+			tags[tag] = "sha256:new"
+		}
+		return map[string]any{}
+	})
+	source := types.ImageIdentifier{ImageTag: aws.String("1.0")}
+
+	if result, err := _ecr.Retag("api", source, "current", false, false); err != nil || result.Changed {
+		t.Fatalf("Expected a tag at the same digest to be left alone, got %+v (%v)", result, err)
+	}
+	if result, err := _ecr.Retag("api", source, "new", false, false); err != nil || !result.Changed || result.Previous != "" {
+		t.Fatalf("Expected a new tag, got %+v (%v)", result, err)
+	}
+	var conflict *TagConflictError
+	if _, err := _ecr.Retag("api", source, "stable", false, false); !errors.As(err, &conflict) || conflict.Digest != "sha256:old" {
+		t.Fatalf("Expected a conflict with sha256:old, got %v", err)
+	}
+
+	immutable = true
+	if _, err := _ecr.Retag("api", source, "stable", true, false); err == nil || !strings.Contains(err.Error(), "immutable tags") || !strings.HasSuffix(err.Error(), "(ImageTagAlreadyExistsException)") {
+		t.Fatalf("Expected an explained immutability error, got %v", err)
+	}
+	immutable = false
+	if result, err := _ecr.Retag("api", source, "stable", true, false); err != nil || !result.Changed || result.Previous != "sha256:old" {
+		t.Fatalf("Expected the tag to be moved from sha256:old, got %+v (%v)", result, err)
+	}
+}
+
+func TestRetagPlan(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "plan.yaml")
+	os.WriteFile(file, []byte(`
+retags:
+  - repos: ["team-*", "team-api"]
+    from: rc-1.4.2
+    to: ["1.4.2", "stable"]
+  - repos: ["web"]
+    from: rc-2.0.0
+    to: ["2.0.0"]
+    force: true
+`), 0o644)
+
+	plan, err := LoadRetagPlan(file)
+	if err != nil {
+		t.Fatalf("Failed to load the plan: %v", err)
+	}
+	tasks, err := plan.Tasks([]string{"web", "team-web", "team-api", "other"})
+	if err != nil {
+		t.Fatalf("Failed to expand the plan: %v", err)
+	}
+	expected := []RetagTask{
+		{Repository: "team-api", From: "rc-1.4.2", To: "1.4.2"},
+		{Repository: "team-api", From: "rc-1.4.2", To: "stable"},
+		{Repository: "team-web", From: "rc-1.4.2", To: "1.4.2"},
+		{Repository: "team-web", From: "rc-1.4.2", To: "stable"},
+		{Repository: "web", From: "rc-2.0.0", To: "2.0.0", Force: true},
+	}
+	if !slices.Equal(tasks, expected) {
+		t.Fatalf("Expected %v, got %v", expected, tasks)
+	}
+
+	if _, err := plan.Tasks([]string{"team-api"}); err == nil {
+		t.Fatalf("Expected an error when a glob matches no repository")
+	}
+
+	nested := &RetagPlan{Retags: []RetagRule{
+		{Repos: []string{"team/*"}, From: "rc", To: []string{"stable"}},
+		{Repos: []string{"team/**"}, From: "rc", To: []string{"latest"}},
+	}}
+	tasks, err = nested.Tasks([]string{"team/api", "team/api/web", "other/api"})
+	if err != nil {
+		t.Fatalf("Failed to expand the plan: %v", err)
+	}
+	expected = []RetagTask{
+		{Repository: "team/api", From: "rc", To: "stable"},
+		{Repository: "team/api", From: "rc", To: "latest"},
+		{Repository: "team/api/web", From: "rc", To: "latest"},
+	}
+	if !slices.Equal(tasks, expected) {
+		t.Fatalf("Expected * to stay within a path segment and ** to cross them, got %v", tasks)
+	}
+	if _, err := (&RetagPlan{Retags: []RetagRule{{Repos: []string{"team/[a"}, From: "rc", To: []string{"stable"}}}}).Tasks([]string{"team/api"}); err == nil {
+		t.Fatalf("Expected an error for an invalid glob")
+	}
+}
+
+func TestSortImages(t *testing.T) {
+	pushed := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	images := []types.ImageDetail{
+		newImage("a", pushed.AddDate(0, 0, 3), "v1.9.3"),
+		newImage("b", pushed.AddDate(0, 0, 1), "v1.10.0", "stable"),
+		newImage("c", pushed.AddDate(0, 0, 2), "latest"),
+		newImage("d", pushed.AddDate(0, 0, 4)),
+		newImage("e", pushed, "v1.10.0-rc.1"),
+	}
+	digests := func(images []types.ImageDetail) []string {
+		var digests []string
+		for _, image := range images {
+			digests = append(digests, *image.ImageDigest)
+		}
+		return digests
+	}
+
+	if err := SortImages(images, SortBySemver); err != nil {
+		t.Fatalf("Failed to sort: %v", err)
+	}
+	if sorted := digests(images); !slices.Equal(sorted, []string{"b", "e", "a", "d", "c"}) {
+		t.Fatalf("Expected the highest version first and images without one by push time, got %v", sorted)
+	}
+	SortImages(images, SortByPushed)
+	if sorted := digests(images); !slices.Equal(sorted, []string{"d", "a", "c", "b", "e"}) {
+		t.Fatalf("Expected the newest first, got %v", sorted)
+	}
+	if err := SortImages(images, "age"); err == nil {
+		t.Fatalf("Expected an error for an invalid sort")
+	}
+
+	filtered, err := FilterImagesByTag(images, "v1.10.*")
+	if err != nil || !slices.Equal(digests(filtered), []string{"b", "e"}) {
+		t.Fatalf("Expected the v1.10 images, got %v (%v)", digests(filtered), err)
+	}
+}
+
+func TestSimulateLifecycle(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	images := []types.ImageDetail{
+		newImage("release-new", now.AddDate(0, 0, -1), "v2.0.0", "stable"),
+		newImage("release-old", now.AddDate(0, 0, -30), "v1.0.0"),
+		newImage("release-older", now.AddDate(0, 0, -60), "v0.9.0"),
+		newImage("dev-new", now.AddDate(0, 0, -2), "dev-abc"),
+		newImage("dev-old", now.AddDate(0, 0, -20), "dev-def"),
+		newImage("untagged", now.AddDate(0, 0, -3)),
+	}
+	policy, err := ParseLifecyclePolicy([]byte(`{"rules": [
+		{"rulePriority": 20, "selection": {"tagStatus": "any", "countType": "sinceImagePushed", "countUnit": "days", "countNumber": 14}, "action": {"type": "expire"}},
+		{"rulePriority": 1, "description": "keep releases", "selection": {"tagStatus": "tagged", "tagPatternList": ["v*"], "countType": "imageCountMoreThan", "countNumber": 2}, "action": {"type": "expire"}},
+		{"rulePriority": 2, "selection": {"tagStatus": "untagged", "countType": "imageCountMoreThan", "countNumber": 1}, "action": {"type": "expire"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse the policy: %v", err)
+	}
+
+	var expired []string
+	for _, expiry := range policy.Simulate(images, now) {
+		expired = append(expired, fmt.Sprintf("%s by %d", *expiry.Image.ImageDigest, expiry.Rule.RulePriority))
+	}
+	// release-old is kept by rule 1, it is not expired by the age of rule 20
+	if expected := []string{"release-older by 1", "dev-old by 20"}; !slices.Equal(expired, expected) {
+		t.Fatalf("Expected %v, got %v", expected, expired)
+	}
+
+	for _, invalid := range []string{
+		`{"rules": []}`,
+		`{"rules": [{"rulePriority": 1, "selection": {"tagStatus": "tagged", "countType": "imageCountMoreThan", "countNumber": 1}, "action": {"type": "expire"}}]}`,
+		`{"rules": [{"rulePriority": 1, "selection": {"tagStatus": "any", "countType": "imageCountMoreThan", "countNumber": 1}, "action": {"type": "expire"}},
+			{"rulePriority": 2, "selection": {"tagStatus": "untagged", "countType": "imageCountMoreThan", "countNumber": 1}, "action": {"type": "expire"}}]}`,
+		`{"rules": [{"rulePriority": 1, "selection": {"tagStatus": "untagged", "countType": "sinceImagePushed", "countNumber": 1}, "action": {"type": "expire"}}]}`,
+	} {
+		if _, err := ParseLifecyclePolicy([]byte(invalid)); err == nil {
+			t.Fatalf("Expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestPruneRules(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	images := []types.ImageDetail{
+		newImage("new", now.AddDate(0, 0, -1), "v3"),
+		newImage("kept", now.AddDate(0, 0, -200), "v2"),
+		newImage("old", now.AddDate(0, 0, -300), "v1"),
+		newImage("release", now.AddDate(0, 0, -400), "release-1"),
+		newImage("untagged", now.AddDate(0, 0, -2)),
+	}
+
+	rules := PruneRules{OlderThan: 90 * 24 * time.Hour, Keep: 2, ProtectedTags: []string{"release-*"}}
+	candidates, err := rules.Select(images, now)
+	if err != nil {
+		t.Fatalf("Failed to select: %v", err)
+	}
+	var selected []string
+	for _, candidate := range candidates {
+		selected = append(selected, *candidate.Image.ImageDigest+" "+candidate.Reason)
+	}
+	if expected := []string{"old pushed 300 days ago", "untagged untagged"}; !slices.Equal(selected, expected) {
+		t.Fatalf("Expected %v, got %v", expected, selected)
+	}
+
+	if candidates, _ := (PruneRules{}).Select(images, now); len(candidates) != 1 {
+		t.Fatalf("Expected only the untagged image without --older-than, got %v", candidates)
+	}
+	// the untagged image may be a platform of a multi-platform push in progress
+	if candidates, _ := (PruneRules{UntaggedOlderThan: 3 * 24 * time.Hour}).Select(images, now); len(candidates) != 0 {
+		t.Fatalf("Expected the recent untagged image to be kept, got %v", candidates)
+	}
+	if _, err := (PruneRules{ProtectedTags: []string{"["}}).Select(images, now); err == nil {
+		t.Fatalf("Expected an error for an invalid pattern")
+	}
+}
+
+func TestImageDependents(t *testing.T) {
+	manifests := map[string]string{
+		"sha256:index":     `{"mediaType":"` + OciImageIndex + `","manifests":[{"digest":"sha256:amd64"},{"digest":"sha256:arm64"}]}`,
+		"sha256:signature": `{"mediaType":"` + OciManifest + `","subject":{"digest":"sha256:index","size":10}}`,
+		"sha256:sbom":      `{"mediaType":"` + OciManifest + `","subject":{"digest":"sha256:old","size":10}}`,
+		"sha256:amd64":     `{"mediaType":"` + OciManifest + `"}`,
+		"sha256:arm64":     `{"mediaType":"` + OciManifest + `"}`,
+	}
+	_ecr, _ := newFakeEcr(t, func(operation string, input map[string]any) any {
+		digest := input["imageIds"].([]any)[0].(map[string]any)["imageDigest"].(string)
+		return map[string]any{"images": []any{map[string]any{
+			"imageId":       map[string]any{"imageDigest": digest},
+			"imageManifest": manifests[digest],
+		}}}
+	})
+	withMediaType := func(digest string, mediaType string) types.ImageDetail {
+		image := newImage(digest, time.Time{})
+		image.ImageManifestMediaType = aws.String(mediaType)
+		return image
+	}
+	images := []types.ImageDetail{
+		withMediaType("sha256:index", OciImageIndex),
+		withMediaType("sha256:amd64", OciManifest),
+		withMediaType("sha256:arm64", OciManifest),
+		withMediaType("sha256:signature", OciManifest),
+		withMediaType("sha256:sbom", OciManifest),
+		withMediaType("sha256:old", DockerManifest),
+	}
+	var candidates []PruneCandidate
+	for _, image := range images[1:] {
+		candidates = append(candidates, PruneCandidate{Image: image})
+	}
+
+	dependents, err := _ecr.ImageDependents("api", images, candidates)
+	if err != nil {
+		t.Fatalf("Failed to read the dependents: %v", err)
+	}
+	protected := dependents.Protected([]string{"sha256:index"})
+	var digests []string
+	for digest := range protected {
+		digests = append(digests, digest)
+	}
+	slices.Sort(digests)
+	// the SBOM of the deleted image is not protected
+	if expected := []string{"sha256:amd64", "sha256:arm64", "sha256:signature"}; !slices.Equal(digests, expected) {
+		t.Fatalf("Expected the platforms and the signature of the kept index, got %v", protected)
+	}
+}
+
+func TestDeleteImages(t *testing.T) {
+	var batches []int
+	_ecr, _ := newFakeEcr(t, func(operation string, input map[string]any) any {
+		imageIds := input["imageIds"].([]any)
+		batches = append(batches, len(imageIds))
+		if len(batches) == 2 {
+			return map[string]any{"failures": []any{map[string]any{"imageId": imageIds[0], "failureCode": "ImageNotFound", "failureReason": "gone"}}}
+		}
+		return map[string]any{}
+	})
+
+	var digests []string
+	for i := range 150 {
+		digests = append(digests, fmt.Sprintf("sha256:%d", i))
+	}
+	failures, err := _ecr.DeleteImages("api", digests)
+	if err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if !slices.Equal(batches, []int{100, 50}) {
+		t.Fatalf("Expected batches of 100 images, got %v", batches)
+	}
+	if len(failures) != 1 || *failures[0].ImageId.ImageDigest != "sha256:100" {
+		t.Fatalf("Expected the failure of the second batch, got %v", failures)
+	}
+}
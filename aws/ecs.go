@@ -1,57 +1,100 @@
 package aws
 
 import (
-	"log"
-	"os"
+	"context"
 
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 )
 
-var activeProfile = "defaul"
+// DescribeServices and DescribeTasks accept a limited number of names per call
+const (
+	ecsDescribeServicesLimit = 10
+	ecsDescribeTasksLimit    = 100
+)
 
 type EcsService struct {
-	client  *ecs.ECS
-	profile string
-	output  string
-	region  string
+	Client *ecs.Client
 }
 
-func getActiveProfile() string {
-	activeProfile = os.Getenv("AWS_PROFILE")
-	return activeProfile
+func (s *EcsService) ListClusters() ([]string, error) {
+	var clusters []string
+	paginator := ecs.NewListClustersPaginator(s.Client, &ecs.ListClustersInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, output.ClusterArns...)
+	}
+	return clusters, nil
 }
 
-func (config *EcsService) init() {
-	mySession := session.Must(session.NewSession())
-	config.client = ecs.New(mySession)
+func (s *EcsService) ListServices(cluster string) ([]types.Service, error) {
+	var arns []string
+	paginator := ecs.NewListServicesPaginator(s.Client, &ecs.ListServicesInput{Cluster: &cluster})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		arns = append(arns, output.ServiceArns...)
+	}
+
+	var services []types.Service
+	for start := 0; start < len(arns); start += ecsDescribeServicesLimit {
+		input := ecs.DescribeServicesInput{Cluster: &cluster, Services: arns[start:min(start+ecsDescribeServicesLimit, len(arns))]}
+		output, err := s.Client.DescribeServices(context.Background(), &input)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, output.Services...)
+	}
+	return services, nil
 }
 
-func (tool *EcsService) listClusters() []*string {
-	maxRes := int64(5)
-	input := ecs.ListClustersInput{MaxResults: &maxRes}
-	response, err := tool.client.ListClusters(&input)
-	if err != nil {
-		log.Fatalf("Failed to retrieve clusters")
+// ListRunningTasks returns the running tasks of the service.
+func (s *EcsService) ListRunningTasks(cluster string, service string) ([]types.Task, error) {
+	var arns []string
+	paginator := ecs.NewListTasksPaginator(s.Client, &ecs.ListTasksInput{Cluster: &cluster, ServiceName: &service, DesiredStatus: types.DesiredStatusRunning})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		arns = append(arns, output.TaskArns...)
+	}
+
+	var tasks []types.Task
+	for start := 0; start < len(arns); start += ecsDescribeTasksLimit {
+		input := ecs.DescribeTasksInput{Cluster: &cluster, Tasks: arns[start:min(start+ecsDescribeTasksLimit, len(arns))]}
+		output, err := s.Client.DescribeTasks(context.Background(), &input)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, output.Tasks...)
 	}
-	return response.ClusterArns
+	return tasks, nil
 }
 
-func (tool *EcsService) listServices(cluster string) []*string {
-	input := ecs.ListServicesInput{Cluster: &cluster}
-	response, err := tool.client.ListServices(&input)
+func (s *EcsService) DescribeTaskDefinition(taskDefinition string) (*types.TaskDefinition, error) {
+	output, err := s.Client.DescribeTaskDefinition(context.Background(), &ecs.DescribeTaskDefinitionInput{TaskDefinition: &taskDefinition})
 	if err != nil {
-		log.Fatalf("Failed to retrieve clusters")
+		return nil, err
 	}
-	return response.ServiceArns
+	return output.TaskDefinition, nil
 }
 
-func (tool *EcsService) describeService(cluster string, service string) *ecs.Service {
-	services := []*string{&service}
-	input := ecs.DescribeServicesInput{Cluster: &cluster, Services: services}
-	response, err := tool.client.DescribeServices(&input)
-	if err != nil {
-		log.Fatalf("Failed to retrieve clusters")
+// ListActiveTaskDefinitions returns the ARNs of the active task definition revisions of the account.
+func (s *EcsService) ListActiveTaskDefinitions() ([]string, error) {
+	var arns []string
+	paginator := ecs.NewListTaskDefinitionsPaginator(s.Client, &ecs.ListTaskDefinitionsInput{Status: types.TaskDefinitionStatusActive})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		arns = append(arns, output.TaskDefinitionArns...)
 	}
-	return response.Services[0]
+	return arns, nil
 }
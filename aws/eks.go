@@ -0,0 +1,189 @@
+package aws
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+)
+
+type EksService struct {
+	Client *eks.Client
+}
+
+func (s *EksService) ListClusters() ([]string, error) {
+	var clusters []string
+
+	paginator := eks.NewListClustersPaginator(s.Client, &eks.ListClustersInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, output.Clusters...)
+	}
+	return clusters, nil
+}
+
+func (s *EksService) DescribeCluster(name string) (*types.Cluster, error) {
+	output, err := s.Client.DescribeCluster(context.Background(), &eks.DescribeClusterInput{Name: &name})
+	if err != nil {
+		return nil, err
+	}
+	return output.Cluster, nil
+}
+
+func (s *EksService) DescribeNodegroup(cluster string, nodegroup string) (*types.Nodegroup, error) {
+	output, err := s.Client.DescribeNodegroup(context.Background(), &eks.DescribeNodegroupInput{ClusterName: &cluster, NodegroupName: &nodegroup})
+	if err != nil {
+		return nil, err
+	}
+	return output.Nodegroup, nil
+}
+
+func (s *EksService) ListAccessEntries(cluster string) ([]string, error) {
+	var principals []string
+
+	paginator := eks.NewListAccessEntriesPaginator(s.Client, &eks.ListAccessEntriesInput{ClusterName: &cluster})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		principals = append(principals, output.AccessEntries...)
+	}
+	return principals, nil
+}
+
+func (s *EksService) DescribeAccessEntry(cluster string, principalArn string) (*types.AccessEntry, error) {
+	output, err := s.Client.DescribeAccessEntry(context.Background(), &eks.DescribeAccessEntryInput{ClusterName: &cluster, PrincipalArn: &principalArn})
+	if err != nil {
+		return nil, err
+	}
+	return output.AccessEntry, nil
+}
+
+func (s *EksService) ListAssociatedAccessPolicies(cluster string, principalArn string) ([]types.AssociatedAccessPolicy, error) {
+	var policies []types.AssociatedAccessPolicy
+
+	paginator := eks.NewListAssociatedAccessPoliciesPaginator(s.Client, &eks.ListAssociatedAccessPoliciesInput{ClusterName: &cluster, PrincipalArn: &principalArn})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, output.AssociatedAccessPolicies...)
+	}
+	return policies, nil
+}
+
+// AccessPolicyArn returns the ARN of an EKS access policy name like AmazonEKSViewPolicy, ARNs are returned as they are.
+func AccessPolicyArn(policy string) string {
+	if strings.HasPrefix(policy, "arn:") {
+		return policy
+	}
+	return "arn:aws:eks::aws:cluster-access-policy/" + policy
+}
+
+func (s *EksService) CreateAccessEntry(cluster string, principalArn string, username string, groups []string) (*types.AccessEntry, error) {
+	input := eks.CreateAccessEntryInput{ClusterName: &cluster, PrincipalArn: &principalArn, KubernetesGroups: groups}
+	if username != "" {
+		input.Username = &username
+	}
+	output, err := s.Client.CreateAccessEntry(context.Background(), &input)
+	if err != nil {
+		return nil, err
+	}
+	return output.AccessEntry, nil
+}
+
+func (s *EksService) AssociateAccessPolicy(cluster string, principalArn string, policyArn string, scope types.AccessScope) error {
+	input := eks.AssociateAccessPolicyInput{ClusterName: &cluster, PrincipalArn: &principalArn, PolicyArn: &policyArn, AccessScope: &scope}
+	_, err := s.Client.AssociateAccessPolicy(context.Background(), &input)
+	return err
+}
+
+func (s *EksService) DeleteAccessEntry(cluster string, principalArn string) error {
+	_, err := s.Client.DeleteAccessEntry(context.Background(), &eks.DeleteAccessEntryInput{ClusterName: &cluster, PrincipalArn: &principalArn})
+	return err
+}
+
+func (s *EksService) ListNodegroups(cluster string) ([]string, error) {
+	var nodegroups []string
+
+	paginator := eks.NewListNodegroupsPaginator(s.Client, &eks.ListNodegroupsInput{ClusterName: &cluster})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		nodegroups = append(nodegroups, output.Nodegroups...)
+	}
+	return nodegroups, nil
+}
+
+func (s *EksService) ListFargateProfiles(cluster string) ([]string, error) {
+	var profiles []string
+
+	paginator := eks.NewListFargateProfilesPaginator(s.Client, &eks.ListFargateProfilesInput{ClusterName: &cluster})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, output.FargateProfileNames...)
+	}
+	return profiles, nil
+}
+
+func (s *EksService) ListAddons(cluster string) ([]string, error) {
+	var addons []string
+
+	paginator := eks.NewListAddonsPaginator(s.Client, &eks.ListAddonsInput{ClusterName: &cluster})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		addons = append(addons, output.Addons...)
+	}
+	return addons, nil
+}
+
+func (s *EksService) DescribeAddon(cluster string, addon string) (*types.Addon, error) {
+	output, err := s.Client.DescribeAddon(context.Background(), &eks.DescribeAddonInput{ClusterName: &cluster, AddonName: &addon})
+	if err != nil {
+		return nil, err
+	}
+	return output.Addon, nil
+}
+
+// DescribeAddonVersions returns the versions of the add-on that are compatible with the Kubernetes version,
+// the default version first.
+func (s *EksService) DescribeAddonVersions(addon string, kubernetesVersion string) ([]string, error) {
+	var versions []string
+
+	input := &eks.DescribeAddonVersionsInput{AddonName: &addon, KubernetesVersion: &kubernetesVersion}
+	paginator := eks.NewDescribeAddonVersionsPaginator(s.Client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range output.Addons {
+			for _, version := range info.AddonVersions {
+				isDefault := false
+				for _, compatibility := range version.Compatibilities {
+					isDefault = isDefault || compatibility.DefaultVersion
+				}
+				if isDefault {
+					versions = append([]string{*version.AddonVersion}, versions...)
+				} else {
+					versions = append(versions, *version.AddonVersion)
+				}
+			}
+		}
+	}
+	return versions, nil
+}
@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// SimulatePrincipalPolicy returns the actions the identity policies of the principal do not
+// allow on the resource. Resource policies and service control policies are not evaluated.
+func SimulatePrincipalPolicy(cfg *aws.Config, principalArn string, actions []string, resource string) ([]string, error) {
+	input := &iam.SimulatePrincipalPolicyInput{PolicySourceArn: &principalArn, ActionNames: actions, ResourceArns: []string{resource}}
+	paginator := iam.NewSimulatePrincipalPolicyPaginator(iam.NewFromConfig(*cfg), input)
+
+	var denied []string
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range output.EvaluationResults {
+			if result.EvalDecision != types.PolicyEvaluationDecisionTypeAllowed {
+				denied = append(denied, *result.EvalActionName)
+			}
+		}
+	}
+	return denied, nil
+}
+
+// PrincipalExists reports whether the IAM role or user of the ARN exists. An error means it
+// could not be verified, e.g. because the principal belongs to another account.
+func PrincipalExists(cfg *aws.Config, principalArn string) (bool, error) {
+	// arn:aws:iam::<account>:role/<path>/<name> or arn:aws:iam::<account>:user/<path>/<name>
+	arn := strings.SplitN(principalArn, ":", 6)
+	if len(arn) != 6 || arn[2] != "iam" {
+		return false, fmt.Errorf("%s is not an IAM role or user ARN", principalArn)
+	}
+	account, err := GetAccountId(cfg)
+	if err != nil {
+		return false, err
+	}
+	if account != arn[4] {
+		return false, fmt.Errorf("%s belongs to account %s, not to account %s of your credentials", principalArn, arn[4], account)
+	}
+
+	kind, path, _ := strings.Cut(arn[5], "/")
+	name := path[strings.LastIndex(path, "/")+1:]
+	client := iam.NewFromConfig(*cfg)
+	switch kind {
+	case "role":
+		_, err = client.GetRole(context.Background(), &iam.GetRoleInput{RoleName: &name})
+	case "user":
+		_, err = client.GetUser(context.Background(), &iam.GetUserInput{UserName: &name})
+	default:
+		return false, fmt.Errorf("%s is not an IAM role or user ARN", principalArn)
+	}
+
+	var noSuchEntity *types.NoSuchEntityException
+	if errors.As(err, &noSuchEntity) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,140 @@
+package aws
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// Orders of ECR images, the newest, largest or highest first
+const (
+	SortByPushed string = "pushed"
+	SortByPulled string = "pulled"
+	SortBySize   string = "size"
+	SortByTag    string = "tag"
+	SortBySemver string = "semver"
+)
+
+var ImageSorts = []string{SortByPushed, SortByPulled, SortBySize, SortByTag, SortBySemver}
+
+func (s *EcrService) ListImages(repository string) ([]types.ImageDetail, error) {
+	var images []types.ImageDetail
+
+	paginator := ecr.NewDescribeImagesPaginator(s.Client, &ecr.DescribeImagesInput{RepositoryName: &repository})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, output.ImageDetails...)
+	}
+	return images, nil
+}
+
+// FilterImagesByTag returns the images with a tag matching the glob, e.g. v1.*
+func FilterImagesByTag(images []types.ImageDetail, pattern string) ([]types.ImageDetail, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid tag pattern %q: %w", pattern, err)
+	}
+	return slices.DeleteFunc(slices.Clone(images), func(image types.ImageDetail) bool {
+		return !slices.ContainsFunc(image.ImageTags, func(tag string) bool {
+			ok, _ := path.Match(pattern, tag)
+			return ok
+		})
+	}), nil
+}
+
+// HighestSemverTag returns the highest tag that is a semantic version, e.g. v1.4.2, nil when there is none.
+func HighestSemverTag(tags []string) *version.Version {
+	var highest *version.Version
+	for _, tag := range tags {
+		v, err := version.ParseSemantic(tag)
+		if err != nil {
+			continue
+		}
+		if highest == nil || highest.LessThan(v) {
+			highest = v
+		}
+	}
+	return highest
+}
+
+// SortImages sorts the images, the newest, largest or highest first. Images without the sort
+// key, e.g. never pulled or without a semver tag, come last ordered by push time.
+func SortImages(images []types.ImageDetail, by string) error {
+	byPushed := func(a, b types.ImageDetail) int {
+		return aws.ToTime(b.ImagePushedAt).Compare(aws.ToTime(a.ImagePushedAt))
+	}
+	var compare func(a, b types.ImageDetail) int
+	switch by {
+	case SortByPushed:
+		compare = byPushed
+	case SortByPulled:
+		compare = func(a, b types.ImageDetail) int {
+			return compareLast(a.LastRecordedPullTime, b.LastRecordedPullTime, func(a, b *time.Time) int { return b.Compare(*a) })
+		}
+	case SortBySize:
+		compare = func(a, b types.ImageDetail) int {
+			return cmp.Compare(aws.ToInt64(b.ImageSizeInBytes), aws.ToInt64(a.ImageSizeInBytes))
+		}
+	case SortByTag:
+		compare = func(a, b types.ImageDetail) int {
+			return compareLast(firstTag(a.ImageTags), firstTag(b.ImageTags), func(a, b *string) int { return strings.Compare(*a, *b) })
+		}
+	case SortBySemver:
+		compare = func(a, b types.ImageDetail) int {
+			return compareLast(HighestSemverTag(a.ImageTags), HighestSemverTag(b.ImageTags), compareVersions)
+		}
+	default:
+		return fmt.Errorf("invalid sort %q, expected one of %s", by, strings.Join(ImageSorts, ", "))
+	}
+	slices.SortStableFunc(images, func(a, b types.ImageDetail) int {
+		if c := compare(a, b); c != 0 {
+			return c
+		}
+		return byPushed(a, b)
+	})
+	return nil
+}
+
+// compareLast compares a and b, nil values last.
+func compareLast[T any](a *T, b *T, compare func(a, b *T) int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return compare(a, b)
+}
+
+// compareVersions orders the higher version first.
+func compareVersions(a *version.Version, b *version.Version) int {
+	switch {
+	case a.LessThan(b):
+		return 1
+	case b.LessThan(a):
+		return -1
+	}
+	return 0
+}
+
+// firstTag returns the first tag in alphabetical order, nil for an untagged image.
+func firstTag(tags []string) *string {
+	if len(tags) == 0 {
+		return nil
+	}
+	tag := slices.Min(tags)
+	return &tag
+}
@@ -0,0 +1,201 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// Tag statuses and count types of the rules of a lifecycle policy
+const (
+	TagStatusTagged   string = "tagged"
+	TagStatusUntagged string = "untagged"
+	TagStatusAny      string = "any"
+
+	CountImagesMoreThan   string = "imageCountMoreThan"
+	CountSinceImagePushed string = "sinceImagePushed"
+)
+
+// LifecyclePolicy is the JSON lifecycle policy of an ECR repository
+type LifecyclePolicy struct {
+	Rules []LifecycleRule `json:"rules"`
+}
+
+// LifecycleRule is a rule of a lifecycle policy, see
+// https://docs.aws.amazon.com/AmazonECR/latest/userguide/lifecycle_policy_parameters.html
+type LifecycleRule struct {
+	RulePriority int    `json:"rulePriority"`
+	Description  string `json:"description,omitempty"`
+	Selection    struct {
+		TagStatus      string   `json:"tagStatus"`
+		TagPrefixList  []string `json:"tagPrefixList,omitempty"`
+		TagPatternList []string `json:"tagPatternList,omitempty"`
+		CountType      string   `json:"countType"`
+		CountUnit      string   `json:"countUnit,omitempty"`
+		CountNumber    int      `json:"countNumber"`
+	} `json:"selection"`
+	Action struct {
+		Type string `json:"type"`
+	} `json:"action"`
+}
+
+// LifecycleExpiry is an image that a rule of the lifecycle policy expires
+type LifecycleExpiry struct {
+	Image types.ImageDetail
+	Rule  LifecycleRule
+}
+
+// GetLifecyclePolicy returns the lifecycle policy text of the repository, "" when it has none.
+func (s *EcrService) GetLifecyclePolicy(repository string) (string, error) {
+	output, err := s.Client.GetLifecyclePolicy(context.Background(), &ecr.GetLifecyclePolicyInput{RepositoryName: &repository})
+	var notFound *types.LifecyclePolicyNotFoundException
+	if errors.As(err, &notFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(output.LifecyclePolicyText), nil
+}
+
+// ParseLifecyclePolicy parses and validates a lifecycle policy the way ECR does when it is put.
+func ParseLifecyclePolicy(text []byte) (*LifecyclePolicy, error) {
+	var policy LifecyclePolicy
+	if err := json.Unmarshal(text, &policy); err != nil {
+		return nil, fmt.Errorf("invalid lifecycle policy: %w", err)
+	}
+	if len(policy.Rules) == 0 {
+		return nil, errors.New("the lifecycle policy has no rules")
+	}
+
+	priorities := map[int]bool{}
+	for _, rule := range policy.Rules {
+		selection := rule.Selection
+		switch {
+		case rule.RulePriority < 1:
+			return nil, fmt.Errorf("rule priority %d must be 1 or more", rule.RulePriority)
+		case priorities[rule.RulePriority]:
+			return nil, fmt.Errorf("rule priority %d is used by more than one rule", rule.RulePriority)
+		case rule.Action.Type != "expire":
+			return nil, fmt.Errorf("rule %d has action %q, expected expire", rule.RulePriority, rule.Action.Type)
+		case selection.CountNumber < 1:
+			return nil, fmt.Errorf("rule %d has countNumber %d, it must be 1 or more", rule.RulePriority, selection.CountNumber)
+		}
+		priorities[rule.RulePriority] = true
+
+		switch selection.TagStatus {
+		case TagStatusTagged:
+			if len(selection.TagPrefixList) == 0 && len(selection.TagPatternList) == 0 {
+				return nil, fmt.Errorf("rule %d selects tagged images, it needs a tagPrefixList or tagPatternList", rule.RulePriority)
+			}
+			if len(selection.TagPrefixList) > 0 && len(selection.TagPatternList) > 0 {
+				return nil, fmt.Errorf("rule %d has both a tagPrefixList and a tagPatternList", rule.RulePriority)
+			}
+		case TagStatusUntagged, TagStatusAny:
+			if len(selection.TagPrefixList) > 0 || len(selection.TagPatternList) > 0 {
+				return nil, fmt.Errorf("rule %d selects %s images, it cannot have a tagPrefixList or tagPatternList", rule.RulePriority, selection.TagStatus)
+			}
+		default:
+			return nil, fmt.Errorf("rule %d has tagStatus %q, expected tagged, untagged or any", rule.RulePriority, selection.TagStatus)
+		}
+
+		switch selection.CountType {
+		case CountImagesMoreThan:
+			if selection.CountUnit != "" {
+				return nil, fmt.Errorf("rule %d counts images, it cannot have a countUnit", rule.RulePriority)
+			}
+		case CountSinceImagePushed:
+			if selection.CountUnit != "days" {
+				return nil, fmt.Errorf("rule %d has countUnit %q, expected days", rule.RulePriority, selection.CountUnit)
+			}
+		default:
+			return nil, fmt.Errorf("rule %d has countType %q, expected %s or %s", rule.RulePriority, selection.CountType, CountImagesMoreThan, CountSinceImagePushed)
+		}
+	}
+
+	slices.SortFunc(policy.Rules, func(a, b LifecycleRule) int { return a.RulePriority - b.RulePriority })
+	if i := slices.IndexFunc(policy.Rules, func(rule LifecycleRule) bool { return rule.Selection.TagStatus == TagStatusAny }); i >= 0 && i != len(policy.Rules)-1 {
+		return nil, fmt.Errorf("rule %d selects any image, it must have the highest rule priority", policy.Rules[i].RulePriority)
+	}
+	return &policy, nil
+}
+
+// Simulate returns the images the policy expires at the time now, in the order of the rules. The
+// rules must be sorted by ParseLifecyclePolicy: they are evaluated by priority, the lowest first, and
+// an image selected by a rule is never expired by a rule of a higher priority number, even when the
+// rule keeps it.
+func (p *LifecyclePolicy) Simulate(images []types.ImageDetail, now time.Time) []LifecycleExpiry {
+	var expiries []LifecycleExpiry
+	remaining := slices.Clone(images)
+	for _, rule := range p.Rules {
+		var selected []types.ImageDetail
+		remaining = slices.DeleteFunc(remaining, func(image types.ImageDetail) bool {
+			if rule.selects(image) {
+				selected = append(selected, image)
+				return true
+			}
+			return false
+		})
+
+		switch rule.Selection.CountType {
+		case CountImagesMoreThan:
+			slices.SortStableFunc(selected, func(a, b types.ImageDetail) int {
+				return aws.ToTime(b.ImagePushedAt).Compare(aws.ToTime(a.ImagePushedAt))
+			})
+			for _, image := range selected[min(rule.Selection.CountNumber, len(selected)):] {
+				expiries = append(expiries, LifecycleExpiry{Image: image, Rule: rule})
+			}
+		case CountSinceImagePushed:
+			cutoff := now.AddDate(0, 0, -rule.Selection.CountNumber)
+			for _, image := range selected {
+				if aws.ToTime(image.ImagePushedAt).Before(cutoff) {
+					expiries = append(expiries, LifecycleExpiry{Image: image, Rule: rule})
+				}
+			}
+		}
+	}
+	return expiries
+}
+
+// selects reports whether the image matches the tag selection of the rule. With a prefix or pattern
+// list every entry must match one of the tags of the image.
+func (r LifecycleRule) selects(image types.ImageDetail) bool {
+	switch r.Selection.TagStatus {
+	case TagStatusUntagged:
+		return len(image.ImageTags) == 0
+	case TagStatusAny:
+		return true
+	}
+	if len(image.ImageTags) == 0 {
+		return false
+	}
+	for _, prefix := range r.Selection.TagPrefixList {
+		if !slices.ContainsFunc(image.ImageTags, func(tag string) bool { return strings.HasPrefix(tag, prefix) }) {
+			return false
+		}
+	}
+	for _, pattern := range r.Selection.TagPatternList {
+		expression := regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$")
+		if !slices.ContainsFunc(image.ImageTags, expression.MatchString) {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes the rule, e.g. "rule 1 (keep the last 10 release images)".
+func (r LifecycleRule) String() string {
+	if r.Description == "" {
+		return fmt.Sprintf("rule %d", r.RulePriority)
+	}
+	return fmt.Sprintf("rule %d (%s)", r.RulePriority, r.Description)
+}
@@ -0,0 +1,143 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// Media types of image manifests and of manifest lists of multi-platform images
+const (
+	DockerManifest     string = "application/vnd.docker.distribution.manifest.v2+json"
+	DockerManifestList string = "application/vnd.docker.distribution.manifest.list.v2+json"
+	OciManifest        string = "application/vnd.oci.image.manifest.v1+json"
+	OciImageIndex      string = "application/vnd.oci.image.index.v1+json"
+)
+
+var ManifestMediaTypes = []string{DockerManifest, DockerManifestList, OciManifest, OciImageIndex}
+
+type Manifest struct {
+	MediaType string `json:"mediaType"`
+	Config    Blob   `json:"config"`
+	Layers    []Blob `json:"layers"`
+	// Manifests of the platforms of a manifest list or image index
+	Manifests []struct {
+		Digest   string   `json:"digest"`
+		Platform Platform `json:"platform"`
+	} `json:"manifests"`
+	// Subject is the image an artifact like a signature or SBOM refers to
+	Subject *Blob `json:"subject,omitempty"`
+}
+
+// Blob is a layer or the config of an image
+type Blob struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+// IsIndex reports whether the manifest is a manifest list or image index of a multi-platform image.
+func (m *Manifest) IsIndex() bool {
+	return m.MediaType == DockerManifestList || m.MediaType == OciImageIndex || len(m.Manifests) > 0
+}
+
+type Platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant"`
+}
+
+func (p Platform) String() string {
+	if p.Variant != "" {
+		return p.OS + "/" + p.Architecture + "/" + p.Variant
+	}
+	return p.OS + "/" + p.Architecture
+}
+
+// GetImagePlatforms returns the platforms of the image, one for single-platform images.
+func (s *EcrService) GetImagePlatforms(registryId string, repository string, imageId types.ImageIdentifier) ([]Platform, error) {
+	input := ecr.BatchGetImageInput{RegistryId: &registryId, RepositoryName: &repository, ImageIds: []types.ImageIdentifier{imageId}, AcceptedMediaTypes: ManifestMediaTypes}
+	output, err := s.Client.BatchGetImage(context.Background(), &input)
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Images) == 0 {
+		return nil, fmt.Errorf("%s: image not found", repository)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal([]byte(*output.Images[0].ImageManifest), &manifest); err != nil {
+		return nil, err
+	}
+	if len(manifest.Manifests) > 0 {
+		var platforms []Platform
+		for _, m := range manifest.Manifests {
+			// attestation manifests of buildx have the platform unknown/unknown
+			if m.Platform.OS != "unknown" {
+				platforms = append(platforms, m.Platform)
+			}
+		}
+		return platforms, nil
+	}
+
+	// the platform of a single-platform image is in its config blob
+	layer, err := s.Client.GetDownloadUrlForLayer(context.Background(), &ecr.GetDownloadUrlForLayerInput{RegistryId: &registryId, RepositoryName: &repository, LayerDigest: &manifest.Config.Digest})
+	if err != nil {
+		return nil, err
+	}
+	response, err := http.Get(*layer.DownloadUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download the config of %s: %s", repository, response.Status)
+	}
+	var platform Platform
+	if err := json.NewDecoder(response.Body).Decode(&platform); err != nil {
+		return nil, err
+	}
+	return []Platform{platform}, nil
+}
+
+// images can be fetched for at most 100 image IDs at a time
+const batchGetImageBatchSize int = 100
+
+// MissingChildManifests returns the digests of the platform manifests of an index that the repository
+// does not have, none for a single-platform manifest.
+func (s *EcrService) MissingChildManifests(repository string, imageManifest string) ([]string, error) {
+	var manifest Manifest
+	if err := json.Unmarshal([]byte(imageManifest), &manifest); err != nil {
+		return nil, err
+	}
+	if !manifest.IsIndex() {
+		return nil, nil
+	}
+
+	var imageIds []types.ImageIdentifier
+	for _, child := range manifest.Manifests {
+		imageIds = append(imageIds, types.ImageIdentifier{ImageDigest: &child.Digest})
+	}
+	found := map[string]bool{}
+	for start := 0; start < len(imageIds); start += batchGetImageBatchSize {
+		batch := imageIds[start:min(start+batchGetImageBatchSize, len(imageIds))]
+		input := ecr.BatchGetImageInput{RepositoryName: &repository, ImageIds: batch, AcceptedMediaTypes: ManifestMediaTypes}
+		output, err := s.Client.BatchGetImage(context.Background(), &input)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range output.Images {
+			found[*image.ImageId.ImageDigest] = true
+		}
+	}
+	var missing []string
+	for _, child := range manifest.Manifests {
+		if !found[child.Digest] {
+			missing = append(missing, child.Digest)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,98 @@
+package aws
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+type policyDocument struct {
+	Statement policyStatements `json:"Statement"`
+}
+
+type policyStatement struct {
+	Effect    string       `json:"Effect"`
+	Principal policyValues `json:"Principal"`
+	Action    policyValues `json:"Action"`
+}
+
+// policyStatements is a single statement or a list of statements
+type policyStatements []policyStatement
+
+func (s *policyStatements) UnmarshalJSON(data []byte) error {
+	var statement policyStatement
+	if err := json.Unmarshal(data, &statement); err == nil {
+		*s = policyStatements{statement}
+		return nil
+	}
+	var statements []policyStatement
+	if err := json.Unmarshal(data, &statements); err != nil {
+		return err
+	}
+	*s = statements
+	return nil
+}
+
+// policyValues is a string, a list of strings or a principal map like {"AWS": [...]}
+type policyValues []string
+
+func (v *policyValues) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*v = policyValues{value}
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal(data, &values); err == nil {
+		*v = values
+		return nil
+	}
+	var principals map[string]policyValues
+	if err := json.Unmarshal(data, &principals); err != nil {
+		return err
+	}
+	*v = principals["AWS"]
+	return nil
+}
+
+// PolicyAllows reports whether the resource policy allows the action to the account or
+// the principal ARN in it. Conditions are not evaluated.
+func PolicyAllows(policy string, action string, account string, principalArn string) (bool, error) {
+	var document policyDocument
+	if err := json.Unmarshal([]byte(policy), &document); err != nil {
+		return false, err
+	}
+
+	allowed := false
+	for _, statement := range document.Statement {
+		if !matchesAny(statement.Action, action) || !matchesPrincipal(statement.Principal, account, principalArn) {
+			continue
+		}
+		if statement.Effect == "Deny" {
+			return false, nil
+		}
+		allowed = true
+	}
+	return allowed, nil
+}
+
+func matchesAny(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(action)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesPrincipal(principals []string, account string, principalArn string) bool {
+	for _, principal := range principals {
+		switch {
+		case principal == "*", principal == account, principal == "arn:aws:iam::"+account+":root":
+			return true
+		case principalArn != "" && principal == principalArn:
+			return true
+		}
+	}
+	return false
+}
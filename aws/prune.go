@@ -0,0 +1,167 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// BatchDeleteImage accepts a limited number of images per call
+const ecrBatchDeleteImageLimit = 100
+
+// PruneRules select the images of a repository to delete
+type PruneRules struct {
+	// OlderThan selects the tagged images pushed longer ago, 0 selects only untagged images
+	OlderThan time.Duration
+	// UntaggedOlderThan selects the untagged images pushed longer ago, the platform manifests
+	// of a multi-platform image are pushed before the index that references them
+	UntaggedOlderThan time.Duration
+	// Keep is the number of most recently pushed tagged images that are never selected
+	Keep int
+	// ProtectedTags are globs of tags whose images are never selected, e.g. release-*
+	ProtectedTags []string
+}
+
+// PruneCandidate is an image selected by the prune rules, with the reason it was selected
+type PruneCandidate struct {
+	Image  types.ImageDetail
+	Reason string
+}
+
+// Select returns the untagged images pushed before now minus UntaggedOlderThan and the tagged
+// images pushed before now minus OlderThan, except the Keep most recently pushed tagged images
+// and the images with a protected tag.
+func (r PruneRules) Select(images []types.ImageDetail, now time.Time) ([]PruneCandidate, error) {
+	for _, pattern := range r.ProtectedTags {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid protected tag pattern %q: %w", pattern, err)
+		}
+	}
+
+	tagged := slices.DeleteFunc(slices.Clone(images), func(image types.ImageDetail) bool { return len(image.ImageTags) == 0 })
+	slices.SortStableFunc(tagged, func(a, b types.ImageDetail) int {
+		return aws.ToTime(b.ImagePushedAt).Compare(aws.ToTime(a.ImagePushedAt))
+	})
+	kept := map[string]bool{}
+	for _, image := range tagged[:min(r.Keep, len(tagged))] {
+		kept[aws.ToString(image.ImageDigest)] = true
+	}
+
+	var candidates []PruneCandidate
+	for _, image := range images {
+		pushed := aws.ToTime(image.ImagePushedAt)
+		switch {
+		case len(image.ImageTags) == 0:
+			if !pushed.After(now.Add(-r.UntaggedOlderThan)) {
+				candidates = append(candidates, PruneCandidate{Image: image, Reason: "untagged"})
+			}
+		case r.OlderThan <= 0 || kept[aws.ToString(image.ImageDigest)] || r.isProtected(image.ImageTags):
+		case pushed.Before(now.Add(-r.OlderThan)):
+			candidates = append(candidates, PruneCandidate{Image: image, Reason: fmt.Sprintf("pushed %d days ago", int(now.Sub(pushed).Hours()/24))})
+		}
+	}
+	return candidates, nil
+}
+
+// isProtected reports whether one of the tags matches a protected tag pattern.
+func (r PruneRules) isProtected(tags []string) bool {
+	for _, pattern := range r.ProtectedTags {
+		for _, tag := range tags {
+			if ok, _ := path.Match(pattern, tag); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ImageDependent is an image that must be kept as long as the image it depends on is kept
+type ImageDependent struct {
+	Digest string
+	Reason string
+}
+
+// ImageDependents maps the digests of images to the images that must be kept with them
+type ImageDependents map[string][]ImageDependent
+
+// ImageDependents returns the platform manifests of the manifest lists and image indexes among the
+// images, and the OCI artifacts among the candidates, like signatures and SBOMs, with the image
+// their subject refers to.
+func (s *EcrService) ImageDependents(repository string, images []types.ImageDetail, candidates []PruneCandidate) (ImageDependents, error) {
+	dependents := ImageDependents{}
+	isCandidate := map[string]bool{}
+	for _, candidate := range candidates {
+		isCandidate[aws.ToString(candidate.Image.ImageDigest)] = true
+	}
+
+	for _, image := range images {
+		digest := aws.ToString(image.ImageDigest)
+		mediaType := aws.ToString(image.ImageManifestMediaType)
+		isIndex := mediaType == DockerManifestList || mediaType == OciImageIndex
+		// only OCI manifests have a subject
+		isReferrer := isCandidate[digest] && (mediaType == OciManifest || mediaType == OciImageIndex)
+		if !isIndex && !isReferrer {
+			continue
+		}
+
+		detail, err := s.GetImage(repository, types.ImageIdentifier{ImageDigest: image.ImageDigest})
+		if err != nil {
+			return nil, err
+		}
+		var manifest Manifest
+		if err := json.Unmarshal([]byte(aws.ToString(detail.ImageManifest)), &manifest); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", digest, err)
+		}
+		for _, m := range manifest.Manifests {
+			dependents[digest] = append(dependents[digest], ImageDependent{Digest: m.Digest, Reason: "platform of multi-platform image " + digest})
+		}
+		if manifest.Subject != nil {
+			dependents[manifest.Subject.Digest] = append(dependents[manifest.Subject.Digest], ImageDependent{Digest: digest, Reason: "artifact of image " + manifest.Subject.Digest})
+		}
+	}
+	return dependents, nil
+}
+
+// Protected returns the images that must be kept because a kept image depends on them, directly or
+// through another dependent, with the reason.
+func (d ImageDependents) Protected(kept []string) map[string]string {
+	protected := map[string]string{}
+	queue := slices.Clone(kept)
+	for len(queue) > 0 {
+		digest := queue[0]
+		queue = queue[1:]
+		for _, dependent := range d[digest] {
+			if _, ok := protected[dependent.Digest]; ok || slices.Contains(kept, dependent.Digest) {
+				continue
+			}
+			protected[dependent.Digest] = dependent.Reason
+			queue = append(queue, dependent.Digest)
+		}
+	}
+	return protected
+}
+
+// DeleteImages deletes the images with the digests and all their tags. The images that could
+// not be deleted are returned as failures.
+func (s *EcrService) DeleteImages(repository string, digests []string) ([]types.ImageFailure, error) {
+	var failures []types.ImageFailure
+	for start := 0; start < len(digests); start += ecrBatchDeleteImageLimit {
+		var imageIds []types.ImageIdentifier
+		for _, digest := range digests[start:min(start+ecrBatchDeleteImageLimit, len(digests))] {
+			imageIds = append(imageIds, types.ImageIdentifier{ImageDigest: aws.String(digest)})
+		}
+		output, err := s.Client.BatchDeleteImage(context.Background(), &ecr.BatchDeleteImageInput{RepositoryName: &repository, ImageIds: imageIds})
+		if err != nil {
+			return nil, err
+		}
+		failures = append(failures, output.Failures...)
+	}
+	return failures, nil
+}
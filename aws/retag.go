@@ -0,0 +1,243 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/aws/smithy-go"
+	"gopkg.in/yaml.v3"
+)
+
+// ecrErrorMessages explain the errors of ECR that come up when tagging images
+var ecrErrorMessages = map[string]string{
+	"AccessDeniedException":          "your credentials are not allowed to do this, check your IAM policies and the repository policy",
+	"ImageAlreadyExistsException":    "the tag already points at this image",
+	"ImageNotFoundException":         "the image does not exist",
+	"ImageTagAlreadyExistsException": "the repository has immutable tags, an existing tag cannot be moved",
+	"KmsException":                   "the KMS key of the repository cannot be used with your credentials",
+	"LimitExceededException":         "the repository has reached its limit of images or tags",
+	"RepositoryNotFoundException":    "the repository does not exist",
+	"ThrottlingException":            "ECR is throttling the requests, try again later",
+}
+
+// ExplainEcrError returns the error of an ECR call in plain language, with the AWS error code.
+func ExplainEcrError(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	message, ok := ecrErrorMessages[apiErr.ErrorCode()]
+	if !ok {
+		return fmt.Errorf("%s (%s)", apiErr.ErrorMessage(), apiErr.ErrorCode())
+	}
+	return fmt.Errorf("%s (%s)", message, apiErr.ErrorCode())
+}
+
+// TagConflictError is returned when the tag exists and points at another image
+type TagConflictError struct {
+	Tag    string
+	Digest string
+}
+
+func (e *TagConflictError) Error() string {
+	return fmt.Sprintf("tag %s already points at %s", e.Tag, e.Digest)
+}
+
+// RetagResult tells what a retag did
+type RetagResult struct {
+	// Digest is the digest the tag points at
+	Digest string
+	// Previous is the digest the tag pointed at before it was moved, "" when the tag is new
+	Previous string
+	// Changed is false when the tag already pointed at the digest
+	Changed bool
+}
+
+// TagDigest returns the digest the tag points at, "" when the repository does not have the tag.
+func (s *EcrService) TagDigest(repository string, tag string) (string, error) {
+	input := ecr.DescribeImagesInput{RepositoryName: &repository, ImageIds: []types.ImageIdentifier{{ImageTag: &tag}}}
+	output, err := s.Client.DescribeImages(context.Background(), &input)
+	var notFound *types.ImageNotFoundException
+	if errors.As(err, &notFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(output.ImageDetails) == 0 {
+		return "", nil
+	}
+	return aws.ToString(output.ImageDetails[0].ImageDigest), nil
+}
+
+// WithThrottlingRetries makes the ECR client retry throttled calls up to 10 times with an exponential
+// backoff, without the retry quota of the standard retryer that runs out when many calls run at once.
+func WithThrottlingRetries(o *ecr.Options) {
+	o.Retryer = retry.AddWithMaxBackoffDelay(retry.NewStandard(func(o *retry.StandardOptions) {
+		o.MaxAttempts = 10
+		o.RateLimiter = ratelimit.None
+	}), 20*time.Second)
+}
+
+// Retag points the tag at the image. A tag that already points at the image is left alone, a tag that
+// points at another image is only moved with force. With dryRun everything is checked but the tag is
+// not written. Errors of ECR are explained with ExplainEcrError.
+func (s *EcrService) Retag(repository string, source types.ImageIdentifier, tag string, force bool, dryRun bool) (*RetagResult, error) {
+	image, err := s.GetImage(repository, source)
+	if err != nil {
+		return nil, ExplainEcrError(err)
+	}
+	result := &RetagResult{Digest: *image.ImageId.ImageDigest}
+
+	missing, err := s.MissingChildManifests(repository, *image.ImageManifest)
+	if err != nil {
+		return nil, ExplainEcrError(err)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("the index references platform manifests that are not in %s: %s", repository, strings.Join(missing, ", "))
+	}
+
+	result.Previous, err = s.TagDigest(repository, tag)
+	if err != nil {
+		return nil, ExplainEcrError(err)
+	}
+	if result.Previous == result.Digest {
+		return result, nil
+	}
+	if result.Previous != "" && !force {
+		return nil, &TagConflictError{Tag: tag, Digest: result.Previous}
+	}
+
+	if dryRun {
+		result.Changed = true
+		return result, nil
+	}
+	_, err = s.PutImage(repository, tag, *image.ImageManifest, aws.ToString(image.ImageManifestMediaType))
+	var exists *types.ImageAlreadyExistsException
+	if errors.As(err, &exists) {
+		// the tag was moved to the image in the meantime
+		return result, nil
+	}
+	if err != nil {
+		return nil, ExplainEcrError(err)
+	}
+	result.Changed = true
+	return result, nil
+}
+
+// RetagRule points the to tags at the image of the from tag in every repository matching one of the globs
+type RetagRule struct {
+	Repos []string `yaml:"repos"`
+	From  string   `yaml:"from"`
+	To    []string `yaml:"to"`
+	Force bool     `yaml:"force"`
+}
+
+// RetagPlan is a plan file of bulk retags, e.g.
+//
+//	retags:
+//	  - repos: ["team-*"]
+//	    from: rc-1.4.2
+//	    to: ["1.4.2", "stable"]
+type RetagPlan struct {
+	Retags []RetagRule `yaml:"retags"`
+}
+
+// RetagTask is a single retag of a plan
+type RetagTask struct {
+	Repository string
+	From       string
+	To         string
+	Force      bool
+}
+
+func LoadRetagPlan(file string) (*RetagPlan, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var plan RetagPlan
+	if err := yaml.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	for i, rule := range plan.Retags {
+		if len(rule.Repos) == 0 || rule.From == "" || len(rule.To) == 0 {
+			return nil, fmt.Errorf("retag %d of %s needs repos, from and to", i+1, file)
+		}
+	}
+	return &plan, nil
+}
+
+// Tasks expands the repository globs of the rules against the repositories, in the order of the rules.
+// A glob that matches no repository is an error, it is most likely a typo.
+func (p *RetagPlan) Tasks(repositories []string) ([]RetagTask, error) {
+	var tasks []RetagTask
+	for _, rule := range p.Retags {
+		var matched []string
+		for _, glob := range rule.Repos {
+			found := false
+			for _, repository := range repositories {
+				ok, err := matchRepository(glob, repository)
+				if err != nil {
+					return nil, fmt.Errorf("invalid repository glob %q: %w", glob, err)
+				}
+				if ok {
+					found = true
+					if !slices.Contains(matched, repository) {
+						matched = append(matched, repository)
+					}
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("no repository matches %q", glob)
+			}
+		}
+		slices.Sort(matched)
+		for _, repository := range matched {
+			for _, to := range rule.To {
+				tasks = append(tasks, RetagTask{Repository: repository, From: rule.From, To: to, Force: rule.Force})
+			}
+		}
+	}
+	return tasks, nil
+}
+
+// matchRepository reports whether the repository name matches the glob. Like path.Match a * does not
+// match the / of a namespaced repository, team/* matches team/api but not team/api/web. A ** path
+// segment matches any number of segments, team/** matches both.
+func matchRepository(glob string, repository string) (bool, error) {
+	if _, err := path.Match(glob, ""); err != nil {
+		return false, err
+	}
+	return matchSegments(strings.Split(glob, "/"), strings.Split(repository, "/")), nil
+}
+
+func matchSegments(globs []string, names []string) bool {
+	if len(globs) == 0 {
+		return len(names) == 0
+	}
+	if globs[0] == "**" {
+		for i := 0; i <= len(names); i++ {
+			if matchSegments(globs[1:], names[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(names) == 0 {
+		return false
+	}
+	ok, _ := path.Match(globs[0], names[0])
+	return ok && matchSegments(globs[1:], names[1:])
+}
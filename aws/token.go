@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+const (
+	eksTokenPrefix  string = "k8s-aws-v1."
+	clusterIdHeader string = "x-k8s-aws-id"
+	// the presigned URL is accepted by EKS for 15 minutes, expire it a minute earlier
+	// so kubectl refreshes before the API server starts rejecting it
+	eksTokenLifetime = 14 * time.Minute
+	// cached tokens are not handed out when they expire within this margin
+	eksTokenRefreshMargin = time.Minute
+)
+
+type EksToken struct {
+	Token      string    `json:"token"`
+	Expiration time.Time `json:"expiration"`
+}
+
+// GetEksToken creates a bearer token for the EKS cluster. The token is a presigned
+// STS GetCallerIdentity URL that includes the cluster name in a signed header.
+func GetEksToken(cfg *aws.Config, clusterName string) (*EksToken, error) {
+	presignClient := sts.NewPresignClient(sts.NewFromConfig(*cfg))
+	request, err := presignClient.PresignGetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{}, func(o *sts.PresignOptions) {
+		o.ClientOptions = append(o.ClientOptions, func(o *sts.Options) {
+			o.APIOptions = append(o.APIOptions, smithyhttp.SetHeaderValue(clusterIdHeader, clusterName), addExpiresQuery)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &EksToken{
+		Token:      eksTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(request.URL)),
+		Expiration: time.Now().Add(eksTokenLifetime),
+	}, nil
+}
+
+// addExpiresQuery adds X-Amz-Expires to the request before it is signed, like
+// aws-iam-authenticator and the AWS CLI do.
+func addExpiresQuery(stack *middleware.Stack) error {
+	return stack.Build.Add(middleware.BuildMiddlewareFunc("AddExpiresQuery", func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+		if request, ok := in.Request.(*smithyhttp.Request); ok {
+			query := request.URL.Query()
+			query.Set("X-Amz-Expires", "60")
+			request.URL.RawQuery = query.Encode()
+		}
+		return next.HandleBuild(ctx, in)
+	}), middleware.After)
+}
+
+// EksTokenCachePath returns the path the token of the cluster is cached at for the profile and region,
+// in ~/.kube/cache/devoops/tokens.
+func EksTokenCachePath(profile string, region string, clusterName string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	cacheFile := strings.ReplaceAll(fmt.Sprintf("%s_%s_%s.json", profile, region, clusterName), string(filepath.Separator), "_")
+	return filepath.Join(home, ".kube", "cache", "devoops", "tokens", cacheFile), nil
+}
+
+// LoadCachedEksToken returns the token cached at path, or nil when there is no
+// cached token or it is about to expire.
+func LoadCachedEksToken(path string) *EksToken {
+	token := ReadCachedEksToken(path)
+	if token == nil || time.Until(token.Expiration) < eksTokenRefreshMargin {
+		return nil
+	}
+	return token
+}
+
+// ReadCachedEksToken returns the token cached at path, also when it has expired, or nil when
+// there is no cached token.
+func ReadCachedEksToken(path string) *EksToken {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var token EksToken
+	if err := json.Unmarshal(content, &token); err != nil {
+		return nil
+	}
+	return &token
+}
+
+func SaveCachedEksToken(path string, token *EksToken) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0600)
+}
@@ -0,0 +1,72 @@
+package aws
+
+import (
+	"encoding/base64"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+func TestGetEksToken(t *testing.T) {
+	cfg := aws.Config{
+		Region:      "eu-west-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "SECRET", ""),
+	}
+
+	token, err := GetEksToken(&cfg, "my-cluster")
+	if err != nil {
+		t.Fatalf("Failed with error %v", err)
+	}
+
+	if !strings.HasPrefix(token.Token, eksTokenPrefix) {
+		t.Fatalf("Expected the token to start with %s, got %s", eksTokenPrefix, token.Token)
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token.Token, eksTokenPrefix))
+	if err != nil {
+		t.Fatalf("Failed to decode the token: %v", err)
+	}
+	presigned, err := url.Parse(string(decoded))
+	if err != nil {
+		t.Fatalf("Failed to parse the presigned URL: %v", err)
+	}
+
+	query := presigned.Query()
+	if query.Get("Action") != "GetCallerIdentity" {
+		t.Fatalf("Expected a GetCallerIdentity request, got %s", query.Get("Action"))
+	}
+	if query.Get("X-Amz-Expires") != "60" {
+		t.Fatalf("Expected X-Amz-Expires=60, got %q", query.Get("X-Amz-Expires"))
+	}
+	if !strings.Contains(query.Get("X-Amz-SignedHeaders"), clusterIdHeader) {
+		t.Fatalf("Expected %s to be signed, got %s", clusterIdHeader, query.Get("X-Amz-SignedHeaders"))
+	}
+}
+
+func TestCachedEksToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens", "token.json")
+
+	if token := LoadCachedEksToken(path); token != nil {
+		t.Fatalf("Expected no cached token")
+	}
+
+	err := SaveCachedEksToken(path, &EksToken{Token: "valid", Expiration: time.Now().Add(10 * time.Minute)})
+	if err != nil {
+		t.Fatalf("Failed to cache token: %v", err)
+	}
+	if token := LoadCachedEksToken(path); token == nil || token.Token != "valid" {
+		t.Fatalf("Expected the cached token, got %v", token)
+	}
+
+	err = SaveCachedEksToken(path, &EksToken{Token: "expiring", Expiration: time.Now().Add(30 * time.Second)})
+	if err != nil {
+		t.Fatalf("Failed to cache token: %v", err)
+	}
+	if token := LoadCachedEksToken(path); token != nil {
+		t.Fatalf("Expected a token that is about to expire to be ignored")
+	}
+}
@@ -5,11 +5,14 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 )
 
 type AwsConfig struct {
 	Region  string
 	Profile string
+	// MfaTokenProvider is asked for a code when the profile assumes a role that requires MFA
+	MfaTokenProvider func() (string, error)
 }
 
 func GetAwsConfig(awsConfig *AwsConfig) (*aws.Config, error) {
@@ -22,6 +25,12 @@ func GetAwsConfig(awsConfig *AwsConfig) (*aws.Config, error) {
 		optFns = append(optFns, config.WithRegion(awsConfig.Region))
 	}
 
+	if awsConfig.MfaTokenProvider != nil {
+		optFns = append(optFns, config.WithAssumeRoleCredentialOptions(func(o *stscreds.AssumeRoleOptions) {
+			o.TokenProvider = awsConfig.MfaTokenProvider
+		}))
+	}
+
 	conf, err := config.LoadDefaultConfig(context.TODO(), optFns...)
 	if err != nil {
 		return nil, err
@@ -4,25 +4,141 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/adpg24/devoops/config"
 	"github.com/adpg24/devoops/kube"
+	"github.com/go-ini/ini"
+	"k8s.io/apimachinery/pkg/util/duration"
 
 	"github.com/spf13/cobra"
 )
 
+var currentContextFormat string
+
+// promptSegment is the data available to the --format template
+type promptSegment struct {
+	*kube.CurrentContext
+	AwsProfile       string     `json:"awsProfile"`
+	SessionExpiry    *time.Time `json:"sessionExpiry,omitempty"`
+	SessionExpiresIn string     `json:"sessionExpiresIn"`
+	Environment      string     `json:"environment"`
+	Color            string     `json:"color"`
+}
+
+var ansiColors = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+}
+
 // currentContextCmd represents the currentContext command
 var currentContextCmd = &cobra.Command{
 	GroupID: "contextGroup",
 	Use:     "current-context",
 	Aliases: []string{"cc"},
 	Short:   "Get current context",
-	Long:    `Get the current context as defined in your kube config`,
+	Long: `Get the current context as defined in your kube config
+
+Use --format to print the current context in a shell prompt, either as "json" or as Go template
+with the fields .Context, .Cluster, .User, .Namespace, .Isolated, .AwsProfile, .SessionExpiry,
+.SessionExpiresIn, .Environment and .Color. The function color wraps text in the ANSI code of a
+color. The environment and color are taken from the environments in ~/.devoops.yaml.
+Nothing is printed when no context is set.
+
+    devoops current-context --format '{{color .Color .Context}}:{{.Namespace}} {{.AwsProfile}} {{.SessionExpiresIn}}'`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if currentContextFormat != "" {
+			printPromptSegment(currentContextFormat)
+			return
+		}
+
 		kubeConfig := kube.NewKubeConfig("")
-		fmt.Println("Current context:", kubeConfig.GetCurrentContext())
+		if current := kube.ReadCurrentContext(); current != nil {
+			fmt.Println("Current context:", current.Context)
+		} else {
+			fmt.Println("Current context: no current context")
+		}
+		if kubeConfig.IsolatedPath != "" {
+			fmt.Println("Mode: isolated to this shell", kubeConfig.IsolatedPath)
+			fmt.Println("Shared config:", kubeConfig.ConfigPath)
+		} else {
+			fmt.Println("Mode: global", kubeConfig.ConfigPath)
+		}
 	},
 }
 
+// printPromptSegment renders the current context without loading the complete kube
+// config, it is called on every prompt render.
+func printPromptSegment(format string) {
+	current := kube.ReadCurrentContext()
+	if current == nil {
+		return
+	}
+
+	segment := promptSegment{CurrentContext: current, AwsProfile: os.Getenv("AWS_PROFILE")}
+	if segment.AwsProfile != "" {
+		if credFile, err := ini.Load(awsCredPath); err == nil {
+			if expiration, ok := sessionExpiration(credFile, segment.AwsProfile); ok {
+				segment.SessionExpiry = &expiration
+				segment.SessionExpiresIn = "expired"
+				if remaining := time.Until(expiration); remaining > 0 {
+					segment.SessionExpiresIn = duration.HumanDuration(remaining)
+				}
+			}
+		}
+	}
+
+	// a broken config must not break the prompt, render it without the environment
+	devoopsConfig, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ %v\n", err)
+		devoopsConfig = &config.Config{}
+	}
+	environment := devoopsConfig.GetEnvironment(current.Context)
+	if environment == nil && segment.AwsProfile != "" {
+		environment = devoopsConfig.GetEnvironment(segment.AwsProfile)
+	}
+	if environment != nil {
+		segment.Environment = environment.Name
+		segment.Color = environment.Color
+	}
+
+	if format == "json" {
+		if err := json.NewEncoder(os.Stdout).Encode(segment); err != nil {
+			log.Fatalf("Failed to write the current context: %v", err)
+		}
+		return
+	}
+
+	tmpl, err := template.New("format").Funcs(template.FuncMap{"color": colorize}).Parse(format)
+	if err != nil {
+		log.Fatalf("❌ Invalid --format: %v", err)
+	}
+	if err := tmpl.Execute(os.Stdout, segment); err != nil {
+		log.Fatalf("❌ Invalid --format: %v", err)
+	}
+}
+
+func colorize(color string, text string) string {
+	code, ok := ansiColors[color]
+	if !ok {
+		return text
+	}
+	return fmt.Sprintf("\033[%sm%s\033[0m", code, text)
+}
+
 func init() {
 	rootCmd.AddCommand(currentContextCmd)
+	currentContextCmd.Flags().StringVarP(&currentContextFormat, "format", "f", "", `Print the current context as "json" or with a Go template`)
 }
@@ -0,0 +1,126 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/adpg24/devoops/aws"
+	"github.com/adpg24/devoops/kube"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	deployRepository string
+	deployTag        string
+	deployDeployment string
+	deployContainer  string
+	deployTimeout    time.Duration
+)
+
+// deployCmd represents the deploy command
+var deployCmd = &cobra.Command{
+	GroupID: "contextGroup",
+	Use:     "deploy",
+	Short:   "Deploy an ECR image tag to a deployment and watch the rollout",
+	Long: `Deploy an ECR image tag to a deployment of the current context and watch the rollout.
+
+The tag is resolved to the digest of the image, so the deployment runs exactly the image the tag
+pointed to when it was deployed. When the rollout fails or does not finish within --timeout, the
+previous image is restored and the events of the failing pods are shown.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		kubeConfig := kube.NewKubeConfig("")
+		checkGuard(kubeContextTarget(kubeConfig, kubeConfig.GetCurrentContext()))
+		client := kube.GetClient(kubeConfig)
+
+		namespace, name, ok := strings.Cut(deployDeployment, "/")
+		if !ok {
+			namespace, name = kubeNamespace(kubeConfig, "", false), deployDeployment
+		}
+
+		cfg, err := aws.GetAwsConfig(&aws.AwsConfig{})
+		if err != nil {
+			log.Fatalf("❌ Failed to load AWS config: %v", err)
+		}
+		_ecr := aws.EcrService{Client: ecr.NewFromConfig(*cfg)}
+		image, err := _ecr.GetImage(deployRepository, types.ImageIdentifier{ImageTag: &deployTag})
+		if err != nil {
+			log.Fatalf("❌ Image %s:%s does not exist: %v", deployRepository, deployTag, err)
+		}
+		uri, err := _ecr.GetRepositoryUri(deployRepository)
+		if err != nil {
+			log.Fatalf("❌ Failed to describe repository %s: %v", deployRepository, err)
+		}
+		reference := uri + "@" + *image.ImageId.ImageDigest
+
+		log.Printf("ℹ Deploying %s:%s (%s) to deployment %s/%s", deployRepository, deployTag, *image.ImageId.ImageDigest, namespace, name)
+		previous, err := kube.SetImage(client, namespace, name, deployContainer, reference)
+		if err != nil {
+			log.Fatalf("❌ Failed to update deployment %s/%s: %v", namespace, name, err)
+		}
+		if previous == reference {
+			log.Printf("ℹ Deployment %s/%s already runs %s:%s", namespace, name, deployRepository, deployTag)
+			return
+		}
+
+		err = waitForRollout(client, namespace, name)
+		if err == nil {
+			log.Printf("✅ Deployed %s:%s to deployment %s/%s", deployRepository, deployTag, namespace, name)
+			return
+		}
+		log.Printf("❌ Rollout failed: %v", err)
+
+		failing, err := kube.FailingPods(client, namespace, name)
+		if err != nil {
+			log.Printf("⚠ Failed to list the pods: %v", err)
+		}
+		for _, status := range failing {
+			state := status.Problem
+			if state == "" {
+				state = string(status.Pod.Status.Phase)
+			}
+			fmt.Printf("%s\t%s\t%d/%d ready\t%d restarts\t%s\n", status.Pod.Name, state, status.Ready, status.Total, status.Restarts, status.LastTermination)
+		}
+		printPodEvents(kubeConfig, namespace, failing)
+
+		log.Printf("ℹ Rolling back deployment %s/%s to %s", namespace, name, previous)
+		if _, err := kube.SetImage(client, namespace, name, deployContainer, previous); err != nil {
+			log.Fatalf("❌ Failed to roll back deployment %s/%s: %v", namespace, name, err)
+		}
+		if err := waitForRollout(client, namespace, name); err != nil {
+			log.Fatalf("❌ Rollback of deployment %s/%s failed: %v", namespace, name, err)
+		}
+		log.Printf("⚠ Rolled back deployment %s/%s to %s", namespace, name, previous)
+		os.Exit(1)
+	},
+}
+
+func waitForRollout(client kubernetes.Interface, namespace string, name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), deployTimeout)
+	defer cancel()
+	return kube.WaitForRollout(ctx, client, namespace, name, func(status string) {
+		log.Printf("⏳ %s", status)
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(deployCmd)
+	deployCmd.Flags().StringVarP(&deployRepository, "repository", "r", "", "The ECR repository name")
+	deployCmd.Flags().StringVarP(&deployTag, "tag", "t", "", "The image tag to deploy")
+	deployCmd.Flags().StringVar(&deployDeployment, "deployment", "", "The deployment as namespace/name, the namespace of the current context when omitted")
+	deployCmd.Flags().StringVarP(&deployContainer, "container", "c", "", "The container to update, required when the deployment has several containers")
+	deployCmd.Flags().DurationVar(&deployTimeout, "timeout", 5*time.Minute, "How long to wait for the rollout before rolling back")
+	deployCmd.MarkFlagRequired("repository")
+	deployCmd.MarkFlagRequired("tag")
+	deployCmd.MarkFlagRequired("deployment")
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// ecrCmd represents the ecr command
+var ecrCmd = &cobra.Command{
+	Use:   "ecr",
+	Short: "Work with AWS ECR repositories and images",
+	Long:  `Work with AWS ECR repositories and the images in them`,
+}
+
+// Output formats of the listing commands
+const (
+	outputTable string = "table"
+	outputJson  string = "json"
+	outputYaml  string = "yaml"
+)
+
+// printStructured prints the value as JSON or YAML, false when the format is a table.
+func printStructured(format string, value any) bool {
+	switch format {
+	case outputTable:
+		return false
+	case outputJson:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(value); err != nil {
+			log.Fatalf("❌ Failed to write JSON: %v", err)
+		}
+	case outputYaml:
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			log.Fatalf("❌ Failed to write YAML: %v", err)
+		}
+	default:
+		log.Fatalf("❌ Invalid output %q, expected table, json or yaml", format)
+	}
+	return true
+}
+
+// formatTime formats the time in the local time zone, - when it is not set.
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// formatBytes formats the size with a binary unit, e.g. 12.3 MiB.
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(ecrCmd)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/adpg24/devoops/aws"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/spf13/cobra"
+)
+
+var ecrCopyParallel int
+
+// ecrLocation is an image of a repository reached with an AWS profile and region
+type ecrLocation struct {
+	Profile    string
+	Region     string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+func (l ecrLocation) String() string {
+	location := l.Profile + "/" + l.Region + "/" + l.Repository
+	if l.Tag != "" {
+		location += ":" + l.Tag
+	}
+	if l.Digest != "" {
+		location += "@" + l.Digest
+	}
+	return location
+}
+
+// parseEcrLocation parses profile/region/repository[:tag][@digest], the repository may contain slashes.
+func parseEcrLocation(location string) (ecrLocation, error) {
+	parts := strings.SplitN(location, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return ecrLocation{}, fmt.Errorf("%q is not a profile/region/repository:tag", location)
+	}
+	l := ecrLocation{Profile: parts[0], Region: parts[1]}
+	name, digest, _ := strings.Cut(parts[2], "@")
+	l.Digest = digest
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name, l.Tag = name[:i], name[i+1:]
+	}
+	l.Repository = name
+	return l, nil
+}
+
+func (l ecrLocation) service() (*aws.EcrService, *awssdk.Config) {
+	cfg, err := aws.GetAwsConfig(&aws.AwsConfig{Profile: l.Profile, Region: l.Region})
+	if err != nil {
+		log.Fatalf("❌ Failed to load AWS config of %s: %v", l.Profile, err)
+	}
+	return &aws.EcrService{Client: ecr.NewFromConfig(*cfg)}, cfg
+}
+
+// ecrCopyCmd represents the ecr copy command
+var ecrCopyCmd = &cobra.Command{
+	Use:   "copy <src-profile/region/repo:tag> <dst-profile/region/repo[:tag]>",
+	Short: "Copy an ECR image to another account or region",
+	Long: `Copy an ECR image with its layers to a repository of another account or region, e.g. to promote
+an image from a build account to a production account. The source may be given by digest with
+@sha256:..., the destination tag defaults to the source tag.
+
+Only the layers the destination does not have are transferred, in parallel, through the pre-signed
+download URLs of the source and multipart uploads to the destination. The manifest is put last,
+multi-platform images are copied with all their platforms.`,
+	Example: `  devoops ecr copy build/eu-west-1/api:1.4.2 prod/eu-west-1/api
+  devoops ecr copy build/eu-west-1/api@sha256:0123... prod/us-east-1/api:1.4.2`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		src, err := parseEcrLocation(args[0])
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		dst, err := parseEcrLocation(args[1])
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if src.Tag == "" && src.Digest == "" {
+			log.Fatalf("❌ %s needs a tag or digest", src)
+		}
+		if dst.Digest != "" {
+			log.Fatalf("❌ %s cannot have a digest, the digest of the copy is the one of the source", dst)
+		}
+		if dst.Tag == "" {
+			dst.Tag = src.Tag
+		}
+		if dst.Tag == "" {
+			log.Fatalf("❌ %s needs a tag when the source is given by digest", dst)
+		}
+
+		srcEcr, _ := src.service()
+		dstEcr, dstCfg := dst.service()
+		checkGuard(awsProfileTarget(dst.Profile, dstCfg))
+
+		imageId := types.ImageIdentifier{ImageTag: &src.Tag}
+		if src.Digest != "" {
+			imageId = types.ImageIdentifier{ImageDigest: &src.Digest}
+		}
+		imageCopy := aws.ImageCopy{
+			Source:                srcEcr,
+			SourceRepository:      src.Repository,
+			SourceImage:           imageId,
+			Destination:           dstEcr,
+			DestinationRepository: dst.Repository,
+			DestinationTag:        dst.Tag,
+			Parallel:              ecrCopyParallel,
+			Progress: func(progress aws.CopyProgress) {
+				fmt.Fprintf(os.Stderr, "\r⏳ %d/%d layers, %s of %s", progress.BlobsDone, progress.Blobs, formatBytes(progress.BytesDone), formatBytes(progress.Bytes))
+			},
+		}
+		result, err := imageCopy.Run(context.Background())
+		if result != nil && result.Copied > 0 || err != nil {
+			fmt.Fprintln(os.Stderr)
+		}
+		if err != nil {
+			log.Fatalf("❌ Failed to copy %s to %s: %v", src, dst, err)
+		}
+		log.Printf("✅ Copied %s to %s (%s), %d of %d layers transferred, %s", src, dst, result.Digest, result.Copied, result.Blobs, formatBytes(result.Bytes))
+	},
+}
+
+func init() {
+	ecrCmd.AddCommand(ecrCopyCmd)
+	ecrCopyCmd.Flags().IntVar(&ecrCopyParallel, "parallel", 4, "Number of layers to transfer at the same time")
+}
@@ -0,0 +1,140 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adpg24/devoops/aws"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ecrImagesRepository string
+	ecrImagesTagPattern string
+	ecrImagesSort       string
+	ecrImagesLatest     int
+)
+
+// imageSummary is an image as listed by ecr images
+type imageSummary struct {
+	Tags        []string         `json:"tags" yaml:"tags"`
+	Digest      string           `json:"digest" yaml:"digest"`
+	PushedAt    *time.Time       `json:"pushedAt" yaml:"pushedAt"`
+	SizeInBytes int64            `json:"sizeInBytes" yaml:"sizeInBytes"`
+	LastPulled  *time.Time       `json:"lastPulledAt,omitempty" yaml:"lastPulledAt,omitempty"`
+	ScanStatus  string           `json:"scanStatus,omitempty" yaml:"scanStatus,omitempty"`
+	Findings    map[string]int32 `json:"findings,omitempty" yaml:"findings,omitempty"`
+}
+
+// ecrImagesCmd represents the ecr images command
+var ecrImagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "List the images of an ECR repository",
+	Long: `List the images of an ECR repository with their tags, digest, push time, size, last pull and
+scan status, the most recently pushed first.
+
+Images are sorted by push time, last pull, size, tag or by their highest semantic version tag,
+e.g. v1.10.0 before v1.9.3. --tag-pattern keeps the images with a tag matching a glob and
+--latest keeps the first images after sorting.`,
+	Args: cobra.NoArgs,
+	Example: `  devoops ecr images -r api
+  devoops ecr images -r api --tag-pattern 'v1.*' --sort semver --latest 5
+  devoops ecr images -r api --sort size -o yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ecr := ecrService()
+		images, err := _ecr.ListImages(ecrImagesRepository)
+		if err != nil {
+			log.Fatalf("❌ Failed to list the images of %s: %v", ecrImagesRepository, aws.ExplainEcrError(err))
+		}
+		if ecrImagesTagPattern != "" {
+			if images, err = aws.FilterImagesByTag(images, ecrImagesTagPattern); err != nil {
+				log.Fatalf("❌ %v", err)
+			}
+		}
+		if err := aws.SortImages(images, ecrImagesSort); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if ecrImagesLatest > 0 && len(images) > ecrImagesLatest {
+			images = images[:ecrImagesLatest]
+		}
+
+		summaries := []imageSummary{}
+		for _, image := range images {
+			tags := slices.Clone(image.ImageTags)
+			slices.Sort(tags)
+			summary := imageSummary{
+				Tags:        tags,
+				Digest:      awssdk.ToString(image.ImageDigest),
+				PushedAt:    image.ImagePushedAt,
+				SizeInBytes: awssdk.ToInt64(image.ImageSizeInBytes),
+				LastPulled:  image.LastRecordedPullTime,
+			}
+			if image.ImageScanStatus != nil {
+				summary.ScanStatus = string(image.ImageScanStatus.Status)
+			}
+			if image.ImageScanFindingsSummary != nil && len(image.ImageScanFindingsSummary.FindingSeverityCounts) > 0 {
+				summary.Findings = image.ImageScanFindingsSummary.FindingSeverityCounts
+			}
+			summaries = append(summaries, summary)
+		}
+		if printStructured(ecrOutput, summaries) {
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TAGS\tDIGEST\tPUSHED\tSIZE\tLAST PULLED\tSCAN")
+		for _, summary := range summaries {
+			tags := strings.Join(summary.Tags, ", ")
+			if tags == "" {
+				tags = "<untagged>"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", tags, shortDigest(summary.Digest), formatTime(summary.PushedAt), formatBytes(summary.SizeInBytes), formatTime(summary.LastPulled), scanStatus(summary))
+		}
+		w.Flush()
+	},
+}
+
+// shortDigest returns the digest cut to 12 hex characters like docker does, e.g. sha256:0123456789ab
+func shortDigest(digest string) string {
+	algorithm, hex, ok := strings.Cut(digest, ":")
+	if !ok || len(hex) <= 12 {
+		return digest
+	}
+	return algorithm + ":" + hex[:12]
+}
+
+// scanStatus returns the status of the scan with the critical and high findings, - when the image was not scanned.
+func scanStatus(summary imageSummary) string {
+	if summary.ScanStatus == "" {
+		return "-"
+	}
+	var findings []string
+	for _, severity := range []string{"CRITICAL", "HIGH"} {
+		if count := summary.Findings[severity]; count > 0 {
+			findings = append(findings, fmt.Sprintf("%d %s", count, strings.ToLower(severity)))
+		}
+	}
+	if len(findings) == 0 {
+		return strings.ToLower(summary.ScanStatus)
+	}
+	return fmt.Sprintf("%s (%s)", strings.ToLower(summary.ScanStatus), strings.Join(findings, ", "))
+}
+
+func init() {
+	ecrCmd.AddCommand(ecrImagesCmd)
+	addEcrListFlags(ecrImagesCmd)
+	ecrImagesCmd.Flags().StringVarP(&ecrImagesRepository, "repository", "r", "", "The ECR repository name")
+	ecrImagesCmd.Flags().StringVar(&ecrImagesTagPattern, "tag-pattern", "", "Only list the images with a tag matching the glob, e.g. 'v1.*'")
+	ecrImagesCmd.Flags().StringVar(&ecrImagesSort, "sort", aws.SortByPushed, "Sort by "+strings.Join(aws.ImageSorts, ", ")+", the newest, largest or highest first")
+	ecrImagesCmd.Flags().IntVar(&ecrImagesLatest, "latest", 0, "Only list the first N images after sorting")
+	ecrImagesCmd.MarkFlagRequired("repository")
+}
@@ -0,0 +1,18 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ecrLifecycleCmd represents the ecr lifecycle command
+var ecrLifecycleCmd = &cobra.Command{
+	Use:   "lifecycle",
+	Short: "Work with the lifecycle policies of ECR repositories",
+}
+
+func init() {
+	ecrCmd.AddCommand(ecrLifecycleCmd)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adpg24/devoops/aws"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ecrLifecycleRepository    string
+	ecrLifecyclePolicy        string
+	ecrLifecycleSkipWorkloads bool
+	ecrLifecycleTimeout       time.Duration
+)
+
+// lifecycleExpiry is an image that the simulated lifecycle policy expires
+type lifecycleExpiry struct {
+	imageSummary `yaml:",inline"`
+	Rule         string   `json:"rule" yaml:"rule"`
+	UsedBy       []string `json:"usedBy,omitempty" yaml:"usedBy,omitempty"`
+}
+
+// ecrLifecycleSimulateCmd represents the ecr lifecycle simulate command
+var ecrLifecycleSimulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Preview the images a lifecycle policy expires",
+	Long: `Preview the images a lifecycle policy expires, without changing the repository.
+
+The policy, a JSON file or else the current policy of the repository, is evaluated locally against
+the images of the repository the way ECR does: rules by priority, the lowest first, an image selected
+by the tag status and tag prefixes or patterns of a rule is only expired by that rule, and the rule
+expires the images beyond its count or pushed more than its number of days ago.
+
+Every kube context and the ECS services of the account of the registry are searched for workloads
+that run or reference the images, the expiring images they use are flagged.`,
+	Args: cobra.NoArgs,
+	Example: `  devoops ecr lifecycle simulate -r api --policy policy.json
+  devoops ecr lifecycle simulate -r api --skip-workloads -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := ecrConfig()
+		_ecr := aws.EcrService{Client: ecr.NewFromConfig(*cfg, aws.WithThrottlingRetries)}
+
+		var text []byte
+		if ecrLifecyclePolicy != "" {
+			var err error
+			if text, err = os.ReadFile(ecrLifecyclePolicy); err != nil {
+				log.Fatalf("❌ Failed to read the policy: %v", err)
+			}
+		} else {
+			current, err := _ecr.GetLifecyclePolicy(ecrLifecycleRepository)
+			if err != nil {
+				log.Fatalf("❌ Failed to get the lifecycle policy of %s: %v", ecrLifecycleRepository, aws.ExplainEcrError(err))
+			}
+			if current == "" {
+				log.Fatalf("❌ %s has no lifecycle policy, pass one with --policy", ecrLifecycleRepository)
+			}
+			text = []byte(current)
+		}
+		policy, err := aws.ParseLifecyclePolicy(text)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+
+		images, err := _ecr.ListImages(ecrLifecycleRepository)
+		if err != nil {
+			log.Fatalf("❌ Failed to list the images of %s: %v", ecrLifecycleRepository, aws.ExplainEcrError(err))
+		}
+		expired := policy.Simulate(images, time.Now())
+
+		usage := repositoryUsage{}
+		if !ecrLifecycleSkipWorkloads && len(expired) > 0 {
+			uri, err := _ecr.GetRepositoryUri(ecrLifecycleRepository)
+			if err != nil {
+				log.Fatalf("❌ Failed to describe repository %s: %v", ecrLifecycleRepository, aws.ExplainEcrError(err))
+			}
+			usage, _ = findRepositoryUsage(cfg, uri, ecrLifecycleTimeout, false)
+		}
+
+		expiries := []lifecycleExpiry{}
+		var size int64
+		inUse := 0
+		for _, expiry := range expired {
+			tags := slices.Clone(expiry.Image.ImageTags)
+			slices.Sort(tags)
+			e := lifecycleExpiry{
+				imageSummary: imageSummary{
+					Tags:        tags,
+					Digest:      awssdk.ToString(expiry.Image.ImageDigest),
+					PushedAt:    expiry.Image.ImagePushedAt,
+					SizeInBytes: awssdk.ToInt64(expiry.Image.ImageSizeInBytes),
+					LastPulled:  expiry.Image.LastRecordedPullTime,
+				},
+				Rule:   expiry.Rule.String(),
+				UsedBy: usage.of(expiry.Image),
+			}
+			size += e.SizeInBytes
+			if len(e.UsedBy) > 0 {
+				inUse++
+			}
+			expiries = append(expiries, e)
+		}
+
+		if !printStructured(ecrOutput, expiries) {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TAGS\tDIGEST\tPUSHED\tSIZE\tRULE\tUSED BY")
+			for _, e := range expiries {
+				tags := strings.Join(e.Tags, ", ")
+				if tags == "" {
+					tags = "<untagged>"
+				}
+				usedBy := "-"
+				if len(e.UsedBy) > 0 {
+					usedBy = "⚠ " + strings.Join(e.UsedBy, ", ")
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", tags, shortDigest(e.Digest), formatTime(e.PushedAt), formatBytes(e.SizeInBytes), e.Rule, usedBy)
+			}
+			w.Flush()
+		}
+
+		log.Printf("ℹ %d of %d images would expire, freeing %s", len(expiries), len(images), formatBytes(size))
+		if inUse > 0 {
+			log.Printf("⚠ %d of the expiring images are still used by running workloads", inUse)
+		}
+	},
+}
+
+func init() {
+	ecrLifecycleCmd.AddCommand(ecrLifecycleSimulateCmd)
+	addEcrListFlags(ecrLifecycleSimulateCmd)
+	ecrLifecycleSimulateCmd.Flags().StringVarP(&ecrLifecycleRepository, "repository", "r", "", "The ECR repository name")
+	ecrLifecycleSimulateCmd.Flags().StringVar(&ecrLifecyclePolicy, "policy", "", "JSON file with the lifecycle policy (default the current policy of the repository)")
+	ecrLifecycleSimulateCmd.Flags().BoolVar(&ecrLifecycleSkipWorkloads, "skip-workloads", false, "Do not search kube contexts and ECS services for workloads using the images")
+	ecrLifecycleSimulateCmd.Flags().DurationVar(&ecrLifecycleTimeout, "timeout", 30*time.Second, "Timeout to search a kube context")
+	ecrLifecycleSimulateCmd.MarkFlagRequired("repository")
+}
@@ -0,0 +1,205 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/adpg24/devoops/aws"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ecrPruneRepository        string
+	ecrPruneOlderThan         string
+	ecrPruneUntaggedOlderThan string
+	ecrPruneKeep              int
+	ecrPruneProtect           []string
+	ecrPruneDelete            bool
+	ecrPruneIgnoreUnreachable bool
+	ecrPruneTimeout           time.Duration
+)
+
+// ecrPruneCmd represents the ecr prune command
+var ecrPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the untagged and old images of an ECR repository that nothing uses",
+	Long: `Delete the untagged images of an ECR repository pushed more than --untagged-older-than ago, and
+with --older-than the tagged images pushed longer ago. It is a dry run unless --delete is given,
+and the deletion is confirmed first.
+
+Images are never deleted when
+  - they are one of the --keep most recently pushed tagged images
+  - one of their tags matches a --protect pattern
+  - a pod in one of your kube contexts runs them, or a deployment, stateful set or daemon set references them
+  - an ECS service or an active task definition revision of the account of the registry references them
+  - they are a platform manifest of a multi-platform image that is kept
+  - they are an artifact, like a signature or SBOM, whose subject is an image that is kept
+
+When a kube context or ECS cluster cannot be searched nothing is deleted, unless --ignore-unreachable
+is given. The reclaimed size is an estimate, layers shared with kept images are not freed.`,
+	Args: cobra.NoArgs,
+	Example: `  devoops ecr prune -r api
+  devoops ecr prune -r api --older-than 90d --keep 20 --protect 'release-*' --delete`,
+	Run: func(cmd *cobra.Command, args []string) {
+		olderThan, err := parseAge(ecrPruneOlderThan)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		untaggedOlderThan, err := parseAge(ecrPruneUntaggedOlderThan)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		cfg := ecrConfig()
+		if ecrPruneDelete {
+			profile := ecrProfile
+			if profile == "" {
+				profile = defaultAwsProfile()
+			}
+			checkGuard(awsProfileTarget(profile, cfg))
+		}
+		_ecr := aws.EcrService{Client: ecr.NewFromConfig(*cfg, aws.WithThrottlingRetries)}
+
+		images, err := _ecr.ListImages(ecrPruneRepository)
+		if err != nil {
+			log.Fatalf("❌ Failed to list the images of %s: %v", ecrPruneRepository, aws.ExplainEcrError(err))
+		}
+		rules := aws.PruneRules{OlderThan: olderThan, UntaggedOlderThan: untaggedOlderThan, Keep: ecrPruneKeep, ProtectedTags: ecrPruneProtect}
+		candidates, err := rules.Select(images, time.Now())
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if len(candidates) == 0 {
+			log.Printf("ℹ Nothing to prune in %s", ecrPruneRepository)
+			return
+		}
+
+		uri, err := _ecr.GetRepositoryUri(ecrPruneRepository)
+		if err != nil {
+			log.Fatalf("❌ Failed to describe repository %s: %v", ecrPruneRepository, aws.ExplainEcrError(err))
+		}
+		usage, skipped := findRepositoryUsage(cfg, uri, ecrPruneTimeout, true)
+
+		kept := map[string]string{}
+		for _, candidate := range candidates {
+			if usedBy := usage.of(candidate.Image); len(usedBy) > 0 {
+				kept[*candidate.Image.ImageDigest] = "used by " + strings.Join(usedBy, ", ")
+			}
+		}
+		dependents, err := _ecr.ImageDependents(ecrPruneRepository, images, candidates)
+		if err != nil {
+			log.Fatalf("❌ Failed to read the manifests of %s: %v", ecrPruneRepository, aws.ExplainEcrError(err))
+		}
+		var remaining []string
+		for _, image := range images {
+			isDeleted := slices.ContainsFunc(candidates, func(c aws.PruneCandidate) bool {
+				return *c.Image.ImageDigest == *image.ImageDigest && kept[*image.ImageDigest] == ""
+			})
+			if !isDeleted {
+				remaining = append(remaining, *image.ImageDigest)
+			}
+		}
+		for digest, reason := range dependents.Protected(remaining) {
+			if kept[digest] == "" {
+				kept[digest] = reason
+			}
+		}
+
+		var digests []string
+		var size int64
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ACTION\tTAGS\tDIGEST\tPUSHED\tSIZE\tREASON")
+		for _, candidate := range candidates {
+			image := candidate.Image
+			tags := slices.Clone(image.ImageTags)
+			slices.Sort(tags)
+			action, reason := "delete", candidate.Reason
+			if kept[*image.ImageDigest] != "" {
+				action, reason = "keep", kept[*image.ImageDigest]
+			} else {
+				digests = append(digests, *image.ImageDigest)
+				size += awssdk.ToInt64(image.ImageSizeInBytes)
+			}
+			if len(tags) == 0 {
+				tags = []string{"<untagged>"}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", action, strings.Join(tags, ", "), shortDigest(*image.ImageDigest), formatTime(image.ImagePushedAt), formatBytes(awssdk.ToInt64(image.ImageSizeInBytes)), reason)
+		}
+		w.Flush()
+
+		if len(digests) == 0 {
+			log.Printf("ℹ Every selected image is still used, nothing to prune")
+			return
+		}
+		if !ecrPruneDelete {
+			log.Printf("ℹ Dry run, %d of %d images would be deleted, reclaiming up to %s. Pass --delete to delete them", len(digests), len(images), formatBytes(size))
+			return
+		}
+		if len(skipped) > 0 && !ecrPruneIgnoreUnreachable {
+			log.Fatalf("❌ Could not search %s for images in use, pass --ignore-unreachable to delete anyway", strings.Join(skipped, ", "))
+		}
+
+		confirmed := false
+		prompt := &survey.Confirm{Message: fmt.Sprintf("Delete %d image(s) of %s, reclaiming up to %s?", len(digests), ecrPruneRepository, formatBytes(size))}
+		if err := survey.AskOne(prompt, &confirmed); err != nil || !confirmed {
+			log.Fatalf("ℹ Alright then, keep your images!\n")
+		}
+
+		failures, err := _ecr.DeleteImages(ecrPruneRepository, digests)
+		if err != nil {
+			log.Fatalf("❌ Failed to delete the images: %v", aws.ExplainEcrError(err))
+		}
+		for _, failure := range failures {
+			log.Printf("❌ Failed to delete %s: %s (%s)", awssdk.ToString(failure.ImageId.ImageDigest), awssdk.ToString(failure.FailureReason), failure.FailureCode)
+		}
+		if len(failures) > 0 {
+			log.Fatalf("❌ Deleted %d of %d images", len(digests)-len(failures), len(digests))
+		}
+		log.Printf("✅ Deleted %d images of %s", len(digests), ecrPruneRepository)
+	},
+}
+
+// parseAge parses an age in days like 90d, or a duration like 36h.
+func parseAge(age string) (time.Duration, error) {
+	if age == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(age, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q, expected a number of days like 90d", age)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(age)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q, expected a number of days like 90d or a duration like 36h", age)
+	}
+	return duration, nil
+}
+
+func init() {
+	ecrCmd.AddCommand(ecrPruneCmd)
+	ecrPruneCmd.Flags().StringVarP(&ecrProfile, "profile", "p", "", "AWS profile of the registry (default $AWS_PROFILE)")
+	ecrPruneCmd.Flags().StringVar(&ecrRegion, "region", "", "AWS region of the registry (default the region of the profile)")
+	ecrPruneCmd.Flags().StringVarP(&ecrPruneRepository, "repository", "r", "", "The ECR repository name")
+	ecrPruneCmd.Flags().StringVar(&ecrPruneOlderThan, "older-than", "", "Also delete the tagged images pushed longer ago, e.g. 90d")
+	ecrPruneCmd.Flags().StringVar(&ecrPruneUntaggedOlderThan, "untagged-older-than", "1d", "Only delete the untagged images pushed longer ago, a multi-platform push may still be in progress")
+	ecrPruneCmd.Flags().IntVar(&ecrPruneKeep, "keep", 0, "Always keep the N most recently pushed tagged images")
+	ecrPruneCmd.Flags().StringSliceVar(&ecrPruneProtect, "protect", []string{"latest"}, "Never delete the images with a tag matching one of the globs, e.g. 'release-*'")
+	ecrPruneCmd.Flags().BoolVar(&ecrPruneDelete, "delete", false, "Delete the images after confirmation instead of a dry run")
+	ecrPruneCmd.Flags().BoolVar(&ecrPruneIgnoreUnreachable, "ignore-unreachable", false, "Delete even when a kube context or ECS cluster cannot be searched")
+	ecrPruneCmd.Flags().DurationVar(&ecrPruneTimeout, "timeout", 30*time.Second, "Timeout to search a kube context")
+	ecrPruneCmd.MarkFlagRequired("repository")
+}
@@ -0,0 +1,124 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adpg24/devoops/aws"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ecrProfile      string
+	ecrRegion       string
+	ecrOutput       string
+	ecrReposPattern string
+	ecrReposSort    string
+)
+
+// repositorySummary is a repository as listed by ecr repos
+type repositorySummary struct {
+	Name          string     `json:"name" yaml:"name"`
+	Uri           string     `json:"uri" yaml:"uri"`
+	TagMutability string     `json:"tagMutability" yaml:"tagMutability"`
+	ScanOnPush    bool       `json:"scanOnPush" yaml:"scanOnPush"`
+	CreatedAt     *time.Time `json:"createdAt" yaml:"createdAt"`
+}
+
+// ecrReposCmd represents the ecr repos command
+var ecrReposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "List the ECR repositories",
+	Args:  cobra.NoArgs,
+	Example: `  devoops ecr repos
+  devoops ecr repos --pattern 'team-*' --sort created -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ecr := ecrService()
+		repositories, err := _ecr.ListRepositories()
+		if err != nil {
+			log.Fatalf("❌ Failed to list the repositories: %v", aws.ExplainEcrError(err))
+		}
+
+		if ecrReposPattern != "" {
+			if _, err := path.Match(ecrReposPattern, ""); err != nil {
+				log.Fatalf("❌ Invalid pattern %q: %v", ecrReposPattern, err)
+			}
+			repositories = slices.DeleteFunc(repositories, func(repository types.Repository) bool {
+				ok, _ := path.Match(ecrReposPattern, *repository.RepositoryName)
+				return !ok
+			})
+		}
+		switch ecrReposSort {
+		case "name":
+			slices.SortFunc(repositories, func(a, b types.Repository) int { return strings.Compare(*a.RepositoryName, *b.RepositoryName) })
+		case "created":
+			slices.SortFunc(repositories, func(a, b types.Repository) int { return awssdk.ToTime(b.CreatedAt).Compare(awssdk.ToTime(a.CreatedAt)) })
+		default:
+			log.Fatalf("❌ Invalid sort %q, expected name or created", ecrReposSort)
+		}
+
+		summaries := []repositorySummary{}
+		for _, repository := range repositories {
+			summary := repositorySummary{
+				Name:          *repository.RepositoryName,
+				Uri:           awssdk.ToString(repository.RepositoryUri),
+				TagMutability: string(repository.ImageTagMutability),
+				CreatedAt:     repository.CreatedAt,
+			}
+			if repository.ImageScanningConfiguration != nil {
+				summary.ScanOnPush = repository.ImageScanningConfiguration.ScanOnPush
+			}
+			summaries = append(summaries, summary)
+		}
+		if printStructured(ecrOutput, summaries) {
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tURI\tTAGS\tSCAN ON PUSH\tCREATED")
+		for _, summary := range summaries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", summary.Name, summary.Uri, strings.ToLower(summary.TagMutability), checkMark(summary.ScanOnPush), formatTime(summary.CreatedAt))
+		}
+		w.Flush()
+	},
+}
+
+// ecrConfig returns the AWS config of --profile and --region.
+func ecrConfig() *awssdk.Config {
+	cfg, err := aws.GetAwsConfig(&aws.AwsConfig{Profile: ecrProfile, Region: ecrRegion})
+	if err != nil {
+		log.Fatalf("❌ Failed to load AWS config: %v", err)
+	}
+	return cfg
+}
+
+// ecrService returns the ECR client of --profile and --region.
+func ecrService() *aws.EcrService {
+	return &aws.EcrService{Client: ecr.NewFromConfig(*ecrConfig(), aws.WithThrottlingRetries)}
+}
+
+// addEcrListFlags adds the flags shared by the listing commands of ecr.
+func addEcrListFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&ecrProfile, "profile", "p", "", "AWS profile of the registry (default $AWS_PROFILE)")
+	cmd.Flags().StringVar(&ecrRegion, "region", "", "AWS region of the registry (default the region of the profile)")
+	cmd.Flags().StringVarP(&ecrOutput, "output", "o", outputTable, "Output format: table, json or yaml")
+}
+
+func init() {
+	ecrCmd.AddCommand(ecrReposCmd)
+	addEcrListFlags(ecrReposCmd)
+	ecrReposCmd.Flags().StringVar(&ecrReposPattern, "pattern", "", "Only list the repositories whose name matches the glob, e.g. 'team-*'")
+	ecrReposCmd.Flags().StringVar(&ecrReposSort, "sort", "name", "Sort by name or created, the newest first")
+}
@@ -0,0 +1,193 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adpg24/devoops/aws"
+	"github.com/adpg24/devoops/kube"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// repositoryUsage maps the tags and digests of the images of a repository to the workloads that
+// run or reference them, e.g. "prod payments/deployment/api"
+type repositoryUsage map[string][]string
+
+// of returns the workloads that run or reference the image by its digest or one of its tags.
+func (u repositoryUsage) of(image types.ImageDetail) []string {
+	var workloads []string
+	for _, reference := range append([]string{awssdk.ToString(image.ImageDigest)}, image.ImageTags...) {
+		for _, workload := range u[reference] {
+			if !slices.Contains(workloads, workload) {
+				workloads = append(workloads, workload)
+			}
+		}
+	}
+	slices.Sort(workloads)
+	return workloads
+}
+
+// findRepositoryUsage searches every kube context, and the ECS clusters of the account and region of
+// the registry, for workloads that run or reference an image of the repository. With taskDefinitions
+// the active task definition revisions are searched too, also when no service uses them. The contexts
+// and clusters that cannot be searched are skipped with a warning and returned as skipped.
+func findRepositoryUsage(cfg *awssdk.Config, uri string, timeout time.Duration, taskDefinitions bool) (repositoryUsage, []string) {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		skipped []string
+	)
+	usage := repositoryUsage{}
+	found := func(reference string, workload string) {
+		mu.Lock()
+		defer mu.Unlock()
+		usage[reference] = append(usage[reference], workload)
+	}
+	skip := func(source string, format string, args ...any) {
+		log.Printf("⚠ "+format, args...)
+		mu.Lock()
+		defer mu.Unlock()
+		skipped = append(skipped, source)
+	}
+
+	kubeConfig := kube.NewKubeConfig("")
+	for _, kubeContext := range kubeConfig.GetContexts() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client, err := kube.GetClientForContext(kubeConfig, kubeContext)
+			if err != nil {
+				skip(kubeContext, "Skipping context %s: %v", kubeContext, err)
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			images, err := kube.FindRepositoryImages(ctx, client, uri)
+			if err != nil {
+				skip(kubeContext, "Failed to search context %s: %v", kubeContext, err)
+				return
+			}
+			for reference, usages := range images {
+				for _, u := range usages {
+					found(reference, fmt.Sprintf("%s %s/%s/%s", kubeContext, u.Namespace, strings.ToLower(u.Kind), u.Name))
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		findRepositoryInEcs(cfg, uri, func(reference string, cluster string, service string, _ int) {
+			found(reference, fmt.Sprintf("ecs %s/service/%s", cluster, service))
+		}, skip)
+	}()
+	if taskDefinitions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			findRepositoryInTaskDefinitions(cfg, uri, found, skip)
+		}()
+	}
+	wg.Wait()
+	slices.Sort(skipped)
+	return usage, skipped
+}
+
+// findRepositoryInEcs searches the ECS services of every cluster for images of the repository, by the
+// digests their running tasks run and the tags or digests their task definition references. found is
+// called with the number of running tasks of the service that run the reference, 0 for a reference
+// only of the task definition.
+func findRepositoryInEcs(cfg *awssdk.Config, uri string, found func(reference string, cluster string, service string, replicas int), skip func(source string, format string, args ...any)) {
+	_ecs := aws.EcsService{Client: ecs.NewFromConfig(*cfg)}
+	clusters, err := _ecs.ListClusters()
+	if err != nil {
+		skip("ecs", "Failed to list the ECS clusters: %v", err)
+		return
+	}
+
+	// task definitions are shared by the services, describe each of them once
+	references := map[string][]string{}
+	for _, cluster := range clusters {
+		services, err := _ecs.ListServices(cluster)
+		if err != nil {
+			skip("ecs "+cluster, "Failed to list the services of ECS cluster %s: %v", cluster, err)
+			continue
+		}
+		clusterName := cluster[strings.LastIndex(cluster, "/")+1:]
+
+		for _, service := range services {
+			tasks, err := _ecs.ListRunningTasks(cluster, *service.ServiceName)
+			if err != nil {
+				skip(fmt.Sprintf("ecs %s/service/%s", clusterName, *service.ServiceName), "Failed to list the tasks of ECS service %s: %v", *service.ServiceName, err)
+				continue
+			}
+			replicas := map[string]int{}
+			for _, task := range tasks {
+				digests := map[string]bool{}
+				for _, container := range task.Containers {
+					if _, ok := kube.ImageReference(awssdk.ToString(container.Image), uri); ok && container.ImageDigest != nil {
+						digests[*container.ImageDigest] = true
+					}
+				}
+				for digest := range digests {
+					replicas[digest]++
+				}
+			}
+			for digest, n := range replicas {
+				found(digest, clusterName, *service.ServiceName, n)
+			}
+
+			taskDefinition := *service.TaskDefinition
+			if _, ok := references[taskDefinition]; !ok {
+				references[taskDefinition] = []string{}
+				definition, err := _ecs.DescribeTaskDefinition(taskDefinition)
+				if err != nil {
+					skip(taskDefinition, "Failed to describe task definition %s: %v", taskDefinition, err)
+				} else {
+					for _, container := range definition.ContainerDefinitions {
+						if reference, ok := kube.ImageReference(awssdk.ToString(container.Image), uri); ok {
+							references[taskDefinition] = append(references[taskDefinition], reference)
+						}
+					}
+				}
+			}
+			for _, reference := range references[taskDefinition] {
+				if replicas[reference] == 0 {
+					found(reference, clusterName, *service.ServiceName, 0)
+				}
+			}
+		}
+	}
+}
+
+func findRepositoryInTaskDefinitions(cfg *awssdk.Config, uri string, found func(reference string, workload string), skip func(source string, format string, args ...any)) {
+	_ecs := aws.EcsService{Client: ecs.NewFromConfig(*cfg)}
+	taskDefinitions, err := _ecs.ListActiveTaskDefinitions()
+	if err != nil {
+		skip("ecs task definitions", "Failed to list the ECS task definitions: %v", err)
+		return
+	}
+	for _, taskDefinition := range taskDefinitions {
+		definition, err := _ecs.DescribeTaskDefinition(taskDefinition)
+		if err != nil {
+			skip(taskDefinition, "Failed to describe task definition %s: %v", taskDefinition, err)
+			continue
+		}
+		workload := fmt.Sprintf("ecs task-definition/%s:%d", awssdk.ToString(definition.Family), definition.Revision)
+		for _, container := range definition.ContainerDefinitions {
+			if reference, ok := kube.ImageReference(awssdk.ToString(container.Image), uri); ok {
+				found(reference, workload)
+			}
+		}
+	}
+}
@@ -0,0 +1,19 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// eksCmd represents the eks command
+var eksCmd = &cobra.Command{
+	Use:   "eks",
+	Short: "Work with AWS EKS clusters",
+	Long:  `Work with AWS EKS clusters and the kube config entries that point to them`,
+}
+
+func init() {
+	rootCmd.AddCommand(eksCmd)
+}
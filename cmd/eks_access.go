@@ -0,0 +1,212 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/adpg24/devoops/aws"
+	"github.com/adpg24/devoops/kube"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	eksAccessClusterName string
+	eksAccessProfile     string
+	eksAccessRegion      string
+	eksAccessContext     string
+	eksAccessDryRun      bool
+)
+
+// eksAccessCmd represents the eks access command
+var eksAccessCmd = &cobra.Command{
+	Use:   "access",
+	Short: "Manage who has access to an EKS cluster",
+	Long: `Manage which IAM roles and users have access to an EKS cluster, with EKS access entries or the
+aws-auth config map, depending on the authentication mode of the cluster.
+
+The aws-auth config map is read and written with the kube context of the cluster, found by its ARN
+or given with --context.`,
+}
+
+// eksAccessCluster is the cluster whose access is managed
+type eksAccessCluster struct {
+	cfg     *awssdk.Config
+	eks     aws.EksService
+	cluster *ekstypes.Cluster
+	// context is the kube context of the cluster, "" when there is none
+	context    string
+	kubeConfig *kube.KubeConfig
+}
+
+func loadEksAccessCluster() *eksAccessCluster {
+	cfg, err := aws.GetAwsConfig(&aws.AwsConfig{Profile: eksAccessProfile, Region: eksAccessRegion})
+	if err != nil {
+		log.Fatalf("❌ Failed to load AWS config: %v", err)
+	}
+	c := &eksAccessCluster{cfg: cfg, eks: aws.EksService{Client: eks.NewFromConfig(*cfg)}, kubeConfig: kube.NewKubeConfig("")}
+
+	c.cluster, err = c.eks.DescribeCluster(eksAccessClusterName)
+	if err != nil {
+		log.Fatalf("❌ Failed to describe EKS cluster %s: %v", eksAccessClusterName, err)
+	}
+
+	c.context = eksAccessContext
+	if c.context == "" {
+		c.context = eksClusterContext(c.kubeConfig, *c.cluster.Arn)
+	}
+	return c
+}
+
+func (c *eksAccessCluster) authenticationMode() ekstypes.AuthenticationMode {
+	if c.cluster.AccessConfig == nil || c.cluster.AccessConfig.AuthenticationMode == "" {
+		return ekstypes.AuthenticationModeConfigMap
+	}
+	return c.cluster.AccessConfig.AuthenticationMode
+}
+
+func (c *eksAccessCluster) usesAccessEntries() bool {
+	return c.authenticationMode() != ekstypes.AuthenticationModeConfigMap
+}
+
+func (c *eksAccessCluster) usesAwsAuth() bool {
+	return c.authenticationMode() != ekstypes.AuthenticationModeApi
+}
+
+func (c *eksAccessCluster) client() kubernetes.Interface {
+	if c.context == "" {
+		log.Fatalf("❌ No kube context for cluster %s, run 'devoops eks sync' or use --context", *c.cluster.Arn)
+	}
+	client, err := kube.GetClientForContext(c.kubeConfig, c.context)
+	if err != nil {
+		log.Fatalf("❌ Failed to create a client for context %s: %v", c.context, err)
+	}
+	return client
+}
+
+// awsAuth reads the aws-auth config map, an empty one when the cluster does not have it.
+func (c *eksAccessCluster) awsAuth() *kube.AwsAuth {
+	awsAuth, err := kube.GetAwsAuth(context.Background(), c.client())
+	if err != nil {
+		log.Fatalf("❌ Failed to read the aws-auth config map: %v", err)
+	}
+	if awsAuth == nil {
+		awsAuth = &kube.AwsAuth{}
+	}
+	for _, arn := range awsAuth.Duplicates() {
+		log.Printf("⚠ %s is mapped more than once in the aws-auth config map", arn)
+	}
+	return awsAuth
+}
+
+// awsAuthIfReachable reads the aws-auth config map when the cluster uses it, nil when it does not or has no kube context.
+func (c *eksAccessCluster) awsAuthIfReachable() *kube.AwsAuth {
+	if !c.usesAwsAuth() {
+		return nil
+	}
+	if c.context == "" {
+		log.Printf("⚠ No kube context for cluster %s, the aws-auth config map is not checked", *c.cluster.Arn)
+		return nil
+	}
+	return c.awsAuth()
+}
+
+// accessEntry returns the access entry of the principal, nil when it has none.
+func (c *eksAccessCluster) accessEntry(principal string) *ekstypes.AccessEntry {
+	if !c.usesAccessEntries() {
+		return nil
+	}
+	principals, err := c.eks.ListAccessEntries(*c.cluster.Name)
+	if err != nil {
+		log.Fatalf("❌ Failed to list the access entries of %s: %v", *c.cluster.Name, err)
+	}
+	for _, p := range principals {
+		if p == principal {
+			entry, err := c.eks.DescribeAccessEntry(*c.cluster.Name, principal)
+			if err != nil {
+				log.Fatalf("❌ Failed to describe the access entry of %s: %v", principal, err)
+			}
+			return entry
+		}
+	}
+	return nil
+}
+
+// checkGuard stops changes to protected clusters unless --yes-i-mean-prod is given.
+func (c *eksAccessCluster) checkGuard() {
+	profile := eksAccessProfile
+	if profile == "" {
+		profile = defaultAwsProfile()
+	}
+	checkGuard(awsProfileTarget(profile, c.cfg))
+	if c.context != "" {
+		checkGuard(kubeContextTarget(c.kubeConfig, c.context))
+	}
+}
+
+// writeAwsAuth shows the changes to the aws-auth config map and writes them unless --dry-run is given.
+func (c *eksAccessCluster) writeAwsAuth(before map[string]string, awsAuth *kube.AwsAuth) {
+	after, err := awsAuth.Data()
+	if err != nil {
+		log.Fatalf("❌ Failed to render the aws-auth config map: %v", err)
+	}
+	for _, key := range []string{"mapRoles", "mapUsers"} {
+		if before[key] == after[key] {
+			continue
+		}
+		fmt.Printf("%s:\n", key)
+		for _, line := range kube.DiffLines(before[key], after[key]) {
+			fmt.Println(line)
+		}
+	}
+	if eksAccessDryRun {
+		return
+	}
+	if err := kube.UpdateAwsAuth(context.Background(), c.client(), awsAuth); err != nil {
+		log.Fatalf("❌ Failed to update the aws-auth config map: %v", err)
+	}
+}
+
+// validatePrincipal stops when the ARN is not an IAM role or user, or when it does not exist.
+func (c *eksAccessCluster) validatePrincipal(principal string) {
+	if strings.Contains(principal, ":assumed-role/") {
+		log.Fatalf("❌ %s is a role session, use the ARN of the role, e.g. arn:aws:iam::<account>:role/<name>", principal)
+	}
+	if !strings.HasPrefix(principal, "arn:aws:iam::") || !(strings.Contains(principal, ":role/") || strings.Contains(principal, ":user/")) {
+		log.Fatalf("❌ %s is not an IAM role or user ARN", principal)
+	}
+	exists, err := aws.PrincipalExists(c.cfg, principal)
+	if err != nil {
+		log.Printf("⚠ Could not verify that %s exists: %v", principal, err)
+		return
+	}
+	if !exists {
+		log.Fatalf("❌ %s does not exist", principal)
+	}
+}
+
+// awsAuthArn returns the role ARN without its path, the aws-auth config map does not match role ARNs with a path.
+func awsAuthArn(principal string) string {
+	prefix, role, ok := strings.Cut(principal, ":role/")
+	if !ok {
+		return principal
+	}
+	return prefix + ":role/" + role[strings.LastIndex(role, "/")+1:]
+}
+
+func init() {
+	eksCmd.AddCommand(eksAccessCmd)
+	eksAccessCmd.PersistentFlags().StringVar(&eksAccessClusterName, "cluster", "", "Name of the EKS cluster")
+	eksAccessCmd.PersistentFlags().StringVarP(&eksAccessProfile, "profile", "p", "", "AWS profile of the cluster (default $AWS_PROFILE)")
+	eksAccessCmd.PersistentFlags().StringVar(&eksAccessRegion, "region", "", "AWS region of the cluster (default the region of the profile)")
+	eksAccessCmd.PersistentFlags().StringVar(&eksAccessContext, "context", "", "Kube context of the cluster (default the context pointing to the cluster)")
+	eksAccessCmd.MarkPersistentFlagRequired("cluster")
+}
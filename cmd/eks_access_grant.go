@@ -0,0 +1,171 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/adpg24/devoops/aws"
+	"github.com/adpg24/devoops/kube"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	grantUsername   string
+	grantGroups     []string
+	grantPolicies   []string
+	grantNamespaces []string
+	grantAwsAuth    bool
+)
+
+// eksAccessGrantCmd represents the eks access grant command
+var eksAccessGrantCmd = &cobra.Command{
+	Use:   "grant <principal-arn>",
+	Short: "Give an IAM role or user access to a cluster",
+	Long: `Give an IAM role or user access to a cluster with an access entry, or with a mapping in the
+aws-auth config map when the cluster only supports the config map or --aws-auth is given.
+
+Access policies are given by name, e.g. AmazonEKSViewPolicy, or by ARN, and apply to the whole
+cluster unless namespaces are given.`,
+	Example: `  devoops eks access grant arn:aws:iam::123456789012:role/developers --cluster dev --policy AmazonEKSEditPolicy --namespace team-a
+  devoops eks access grant arn:aws:iam::123456789012:role/admins --cluster dev --group system:masters --aws-auth --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		principal := args[0]
+		c := loadEksAccessCluster()
+		c.validatePrincipal(principal)
+
+		useAwsAuth := grantAwsAuth || !c.usesAccessEntries()
+		if useAwsAuth && !c.usesAwsAuth() {
+			log.Fatalf("❌ Cluster %s only supports access entries, grant without --aws-auth", *c.cluster.Name)
+		}
+		if useAwsAuth {
+			grantAwsAuthMapping(c, principal)
+		} else {
+			grantAccessEntry(c, principal)
+		}
+	},
+}
+
+func grantAwsAuthMapping(c *eksAccessCluster, principal string) {
+	if len(grantPolicies) > 0 || len(grantNamespaces) > 0 {
+		log.Fatalf("❌ --policy and --namespace need access entries, the aws-auth config map only maps to groups")
+	}
+	if len(grantGroups) == 0 {
+		log.Fatalf("❌ --group is required to grant access with the aws-auth config map")
+	}
+
+	arn := awsAuthArn(principal)
+	if arn != principal {
+		log.Printf("ℹ The aws-auth config map does not match role paths, mapping %s", arn)
+	}
+	if entry := c.accessEntry(principal); entry != nil {
+		log.Printf("⚠ %s also has an access entry, which takes precedence over the aws-auth config map", principal)
+	}
+
+	mapping := kube.AwsAuthMapping{Username: grantUsername, Groups: grantGroups}
+	if mapping.Username == "" {
+		mapping.Username = arn[strings.LastIndex(arn, "/")+1:]
+	}
+	if strings.Contains(arn, ":role/") {
+		mapping.RoleArn = arn
+	} else {
+		mapping.UserArn = arn
+	}
+
+	awsAuth := c.awsAuth()
+	if existing := awsAuth.Find(arn); existing != nil {
+		if existing.Username == mapping.Username && slices.Equal(existing.Groups, mapping.Groups) {
+			fmt.Printf("ℹ %s is already mapped as %s with groups %s\n", arn, existing.Username, strings.Join(existing.Groups, ", "))
+			return
+		}
+		log.Fatalf("❌ %s is already mapped as %s with groups %s, revoke it first", arn, existing.Username, strings.Join(existing.Groups, ", "))
+	}
+
+	before, err := awsAuth.Data()
+	if err != nil {
+		log.Fatalf("❌ Failed to render the aws-auth config map: %v", err)
+	}
+	awsAuth.Set(mapping)
+	if !eksAccessDryRun {
+		c.checkGuard()
+	}
+	c.writeAwsAuth(before, awsAuth)
+	if !eksAccessDryRun {
+		fmt.Printf("✅ Mapped %s to %s in the aws-auth config map of %s\n", arn, mapping.Username, *c.cluster.Name)
+	}
+}
+
+func grantAccessEntry(c *eksAccessCluster, principal string) {
+	if len(grantPolicies) == 0 && len(grantGroups) == 0 {
+		log.Fatalf("❌ --policy or --group is required, an access entry without them gives no access")
+	}
+	if len(grantNamespaces) > 0 && len(grantPolicies) == 0 {
+		log.Fatalf("❌ --namespace scopes the access policies, it needs --policy")
+	}
+
+	if entry := c.accessEntry(principal); entry != nil {
+		log.Fatalf("❌ %s already has an access entry as %s, revoke it first", principal, awssdk.ToString(entry.Username))
+	}
+	if awsAuth := c.awsAuthIfReachable(); awsAuth != nil {
+		if mapping := awsAuth.Find(awsAuthArn(principal)); mapping != nil {
+			log.Printf("⚠ %s is also mapped as %s in the aws-auth config map, the access entry will take precedence", principal, mapping.Username)
+		}
+	}
+
+	scope := ekstypes.AccessScope{Type: ekstypes.AccessScopeTypeCluster}
+	if len(grantNamespaces) > 0 {
+		scope = ekstypes.AccessScope{Type: ekstypes.AccessScopeTypeNamespace, Namespaces: grantNamespaces}
+	}
+	scopeName := string(scope.Type)
+	if len(scope.Namespaces) > 0 {
+		scopeName += " " + strings.Join(scope.Namespaces, ", ")
+	}
+
+	entry := fmt.Sprintf("+ access entry %s", principal)
+	if grantUsername != "" {
+		entry += " as " + grantUsername
+	}
+	if len(grantGroups) > 0 {
+		entry += " with groups " + strings.Join(grantGroups, ", ")
+	}
+	fmt.Println(entry)
+	for _, policy := range grantPolicies {
+		fmt.Printf("+ access policy %s on %s\n", aws.AccessPolicyArn(policy), scopeName)
+	}
+	if eksAccessDryRun {
+		return
+	}
+
+	c.checkGuard()
+	if _, err := c.eks.CreateAccessEntry(*c.cluster.Name, principal, grantUsername, grantGroups); err != nil {
+		log.Fatalf("❌ Failed to create the access entry of %s: %v", principal, err)
+	}
+	for _, policy := range grantPolicies {
+		if err := c.eks.AssociateAccessPolicy(*c.cluster.Name, principal, aws.AccessPolicyArn(policy), scope); err != nil {
+			// an access entry without all of its policies would make grant refuse to run again
+			log.Printf("❌ Failed to associate %s with %s: %v", policy, principal, err)
+			if err := c.eks.DeleteAccessEntry(*c.cluster.Name, principal); err != nil {
+				log.Fatalf("❌ Failed to roll back the access entry of %s, revoke it before granting again: %v", principal, err)
+			}
+			log.Fatalf("ℹ Rolled back the access entry of %s", principal)
+		}
+	}
+	fmt.Printf("✅ Granted %s access to %s\n", principal, *c.cluster.Name)
+}
+
+func init() {
+	eksAccessCmd.AddCommand(eksAccessGrantCmd)
+	eksAccessGrantCmd.Flags().StringVar(&grantUsername, "username", "", "Kubernetes username of the principal (default the name of the role or user with --aws-auth)")
+	eksAccessGrantCmd.Flags().StringSliceVar(&grantGroups, "group", nil, "Kubernetes group of the principal, can be repeated")
+	eksAccessGrantCmd.Flags().StringSliceVar(&grantPolicies, "policy", nil, "EKS access policy name or ARN, can be repeated")
+	eksAccessGrantCmd.Flags().StringSliceVar(&grantNamespaces, "namespace", nil, "Namespace to scope the access policies to, can be repeated (default the whole cluster)")
+	eksAccessGrantCmd.Flags().BoolVar(&grantAwsAuth, "aws-auth", false, "Map the principal in the aws-auth config map instead of an access entry")
+	eksAccessGrantCmd.Flags().BoolVar(&eksAccessDryRun, "dry-run", false, "Show the changes without making them")
+}
@@ -0,0 +1,81 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/adpg24/devoops/kube"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/spf13/cobra"
+)
+
+// eksAccessListCmd represents the eks access list command
+var eksAccessListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the access entries and aws-auth mappings of a cluster",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c := loadEksAccessCluster()
+		fmt.Printf("Cluster:             %s\n", *c.cluster.Arn)
+		fmt.Printf("Authentication mode: %s\n\n", c.authenticationMode())
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PRINCIPAL\tSOURCE\tUSERNAME\tGROUPS\tPOLICIES")
+
+		mapped := map[string]bool{}
+		if c.usesAccessEntries() {
+			principals, err := c.eks.ListAccessEntries(*c.cluster.Name)
+			if err != nil {
+				log.Fatalf("❌ Failed to list the access entries of %s: %v", *c.cluster.Name, err)
+			}
+			for _, principal := range principals {
+				entry, err := c.eks.DescribeAccessEntry(*c.cluster.Name, principal)
+				if err != nil {
+					log.Fatalf("❌ Failed to describe the access entry of %s: %v", principal, err)
+				}
+				policies, err := c.eks.ListAssociatedAccessPolicies(*c.cluster.Name, principal)
+				if err != nil {
+					log.Fatalf("❌ Failed to list the access policies of %s: %v", principal, err)
+				}
+				var names []string
+				for _, policy := range policies {
+					name := (*policy.PolicyArn)[strings.LastIndex(*policy.PolicyArn, "/")+1:]
+					if len(policy.AccessScope.Namespaces) > 0 {
+						name += " (" + strings.Join(policy.AccessScope.Namespaces, ", ") + ")"
+					}
+					names = append(names, name)
+				}
+				mapped[awsAuthArn(principal)] = true
+				fmt.Fprintf(w, "%s\taccess entry\t%s\t%s\t%s\n", principal, awssdk.ToString(entry.Username), strings.Join(entry.KubernetesGroups, ", "), strings.Join(names, ", "))
+			}
+		}
+
+		var conflicts []string
+		if c.usesAwsAuth() {
+			awsAuth := c.awsAuth()
+			for _, mappings := range [][]kube.AwsAuthMapping{awsAuth.Roles, awsAuth.Users} {
+				for _, mapping := range mappings {
+					if mapped[mapping.Arn()] {
+						conflicts = append(conflicts, mapping.Arn())
+					}
+					fmt.Fprintf(w, "%s\taws-auth\t%s\t%s\t\n", mapping.Arn(), mapping.Username, strings.Join(mapping.Groups, ", "))
+				}
+			}
+		}
+		w.Flush()
+
+		for _, arn := range conflicts {
+			log.Printf("⚠ %s has an access entry and an aws-auth mapping, the access entry takes precedence", arn)
+		}
+	},
+}
+
+func init() {
+	eksAccessCmd.AddCommand(eksAccessListCmd)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/spf13/cobra"
+)
+
+// eksAccessRevokeCmd represents the eks access revoke command
+var eksAccessRevokeCmd = &cobra.Command{
+	Use:   "revoke <principal-arn>",
+	Short: "Remove the access of an IAM role or user to a cluster",
+	Long: `Remove the access entry of an IAM role or user and its mapping in the aws-auth config map,
+wherever the principal is found.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		principal := args[0]
+		c := loadEksAccessCluster()
+
+		entry := c.accessEntry(principal)
+		var awsAuthFound bool
+		var before map[string]string
+		awsAuth := c.awsAuthIfReachable()
+		if awsAuth != nil {
+			var err error
+			if before, err = awsAuth.Data(); err != nil {
+				log.Fatalf("❌ Failed to render the aws-auth config map: %v", err)
+			}
+			awsAuthFound = awsAuth.Remove(awsAuthArn(principal))
+		}
+		if entry == nil && !awsAuthFound {
+			fmt.Printf("ℹ %s has no access entry or aws-auth mapping in %s\n", principal, *c.cluster.Name)
+			return
+		}
+
+		if entry != nil {
+			fmt.Printf("- access entry %s as %s\n", principal, awssdk.ToString(entry.Username))
+		}
+		if !eksAccessDryRun {
+			c.checkGuard()
+		}
+		if awsAuthFound {
+			c.writeAwsAuth(before, awsAuth)
+		}
+		if eksAccessDryRun {
+			return
+		}
+		if entry != nil {
+			if err := c.eks.DeleteAccessEntry(*c.cluster.Name, principal); err != nil {
+				log.Fatalf("❌ Failed to delete the access entry of %s: %v", principal, err)
+			}
+		}
+		fmt.Printf("✅ Revoked the access of %s to %s\n", principal, *c.cluster.Name)
+	},
+}
+
+func init() {
+	eksAccessCmd.AddCommand(eksAccessRevokeCmd)
+	eksAccessRevokeCmd.Flags().BoolVar(&eksAccessDryRun, "dry-run", false, "Show the changes without making them")
+}
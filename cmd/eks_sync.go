@@ -0,0 +1,232 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adpg24/devoops/aws"
+	"github.com/adpg24/devoops/kube"
+	"github.com/adpg24/devoops/util"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+const execApiVersion string = "client.authentication.k8s.io/v1beta1"
+
+var (
+	eksSyncProfiles    []string
+	eksSyncAllProfiles bool
+	eksSyncRegions     []string
+	eksSyncDryRun      bool
+)
+
+// eksSyncCmd represents the eks sync command
+var eksSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Add the EKS clusters of your AWS profiles to your kube config",
+	Long: `Add the EKS clusters of your AWS profiles to your kube config.
+
+Every cluster found in the selected profiles and regions gets a cluster, user and context
+entry. Contexts are named <account-alias>/<region>/<cluster> and the users authenticate
+with 'devoops eks token'. Contexts created by this command whose cluster no longer exists
+are removed.`,
+	Run: syncEks,
+}
+
+func syncEks(cmd *cobra.Command, args []string) {
+	profiles := selectAwsProfiles(eksSyncProfiles, eksSyncAllProfiles)
+
+	kubeConfig := kube.NewKubeConfig("")
+	before := kubeConfig.Config.DeepCopy()
+	executable := devoopsExecutable()
+
+	// account/region pairs that were listed successfully, only their contexts can be pruned
+	scanned := map[string]bool{}
+	found := map[string]bool{}
+
+	for _, profile := range profiles {
+		profileConfig, err := aws.GetAwsConfig(&aws.AwsConfig{Profile: profile})
+		if err != nil {
+			log.Printf("⚠ Skipping profile %s, failed to load AWS config: %v", profile, err)
+			continue
+		}
+		accountId, err := aws.GetAccountId(profileConfig)
+		if err != nil {
+			log.Printf("⚠ Skipping profile %s, failed to retrieve the account: %v", profile, err)
+			continue
+		}
+		accountName, _ := aws.GetAccountName(profileConfig)
+
+		regions := eksSyncRegions
+		if len(regions) == 0 {
+			regions = []string{region}
+			if profileConfig.Region != "" {
+				regions = []string{profileConfig.Region}
+			}
+		}
+
+		for _, r := range regions {
+			cfg, err := aws.GetAwsConfig(&aws.AwsConfig{Profile: profile, Region: r})
+			util.HandleErr(err, "Failed to load AWS config: %v", err)
+
+			_eks := aws.EksService{Client: eks.NewFromConfig(*cfg)}
+			clusters, err := _eks.ListClusters()
+			if err != nil {
+				log.Printf("⚠ Failed to list the EKS clusters of %s in %s: %v", profile, r, err)
+				continue
+			}
+			scanned[accountId+"/"+r] = true
+
+			for _, name := range clusters {
+				cluster, err := _eks.DescribeCluster(name)
+				if err != nil {
+					log.Printf("⚠ Failed to describe EKS cluster %s: %v", name, err)
+					continue
+				}
+				found[*cluster.Arn] = true
+				contextName := fmt.Sprintf("%s/%s/%s", accountName, r, name)
+				setEksEntries(kubeConfig.Config, contextName, cluster, profile, r, executable)
+			}
+		}
+	}
+
+	pruneEksContexts(kubeConfig, scanned, found)
+
+	changes := kube.Diff(before, kubeConfig.Config)
+	if len(changes) == 0 {
+		log.Println("ℹ Your kube config is up to date")
+		return
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	if eksSyncDryRun {
+		return
+	}
+	kubeConfig.Write()
+	log.Printf("Updated %s with %d change(s)", kubeConfig.ConfigPath, len(changes))
+}
+
+func setEksEntries(config *api.Config, contextName string, cluster *types.Cluster, profile string, region string, executable string) {
+	arn := *cluster.Arn
+
+	kubeCluster, ok := config.Clusters[arn]
+	if !ok {
+		kubeCluster = api.NewCluster()
+		config.Clusters[arn] = kubeCluster
+	}
+	kubeCluster.Server = *cluster.Endpoint
+	if cluster.CertificateAuthority != nil && cluster.CertificateAuthority.Data != nil {
+		caData, err := base64.StdEncoding.DecodeString(*cluster.CertificateAuthority.Data)
+		util.HandleErr(err, "Failed to decode the certificate authority of %s: %v", arn, err)
+		kubeCluster.CertificateAuthorityData = caData
+	}
+
+	user, ok := config.AuthInfos[arn]
+	if !ok {
+		user = api.NewAuthInfo()
+		config.AuthInfos[arn] = user
+	}
+	user.Exec = &api.ExecConfig{
+		APIVersion:      execApiVersion,
+		Command:         executable,
+		Args:            []string{"eks", "token", "--cluster-name", *cluster.Name, "--region", region, "--profile", profile},
+		InteractiveMode: api.IfAvailableExecInteractiveMode,
+	}
+
+	context, ok := config.Contexts[contextName]
+	if !ok {
+		context = api.NewContext()
+		config.Contexts[contextName] = context
+	}
+	context.Cluster = arn
+	context.AuthInfo = arn
+}
+
+// pruneEksContexts removes the contexts created by 'eks sync' for clusters that no
+// longer exist in one of the scanned account/region pairs.
+func pruneEksContexts(kubeConfig *kube.KubeConfig, scanned map[string]bool, found map[string]bool) {
+	for name, context := range kubeConfig.Config.Contexts {
+		user, ok := kubeConfig.Config.AuthInfos[context.AuthInfo]
+		if !ok || !isEksSyncUser(user) {
+			continue
+		}
+		// arn:aws:eks:<region>:<account>:cluster/<name>
+		arn := strings.Split(context.Cluster, ":")
+		if len(arn) != 6 || arn[2] != "eks" {
+			continue
+		}
+		if scanned[arn[4]+"/"+arn[3]] && !found[context.Cluster] {
+			kubeConfig.DeleteContext(name)
+		}
+	}
+}
+
+func isEksSyncUser(user *api.AuthInfo) bool {
+	return user.Exec != nil &&
+		strings.Contains(filepath.Base(user.Exec.Command), "devoops") &&
+		len(user.Exec.Args) > 2 && slices.Equal(user.Exec.Args[:2], []string{"eks", "token"})
+}
+
+// eksContextProfile returns the AWS profile the user of the context authenticates with, "" when unknown.
+func eksContextProfile(kubeConfig *kube.KubeConfig, kubeContext string) string {
+	context, ok := kubeConfig.Config.Contexts[kubeContext]
+	if !ok {
+		return ""
+	}
+	user, ok := kubeConfig.Config.AuthInfos[context.AuthInfo]
+	if !ok || user.Exec == nil {
+		return ""
+	}
+	if i := slices.Index(user.Exec.Args, "--profile"); i >= 0 && i+1 < len(user.Exec.Args) {
+		return user.Exec.Args[i+1]
+	}
+	for _, env := range user.Exec.Env {
+		if env.Name == "AWS_PROFILE" {
+			return env.Value
+		}
+	}
+	return ""
+}
+
+// eksClusterContext returns the first context, by name, pointing to the cluster ARN, "" when there is none.
+func eksClusterContext(kubeConfig *kube.KubeConfig, clusterArn string) string {
+	var contexts []string
+	for name, context := range kubeConfig.Config.Contexts {
+		if context.Cluster == clusterArn {
+			contexts = append(contexts, name)
+		}
+	}
+	if len(contexts) == 0 {
+		return ""
+	}
+	return slices.Min(contexts)
+}
+
+func devoopsExecutable() string {
+	if path, err := exec.LookPath("devoops"); err == nil {
+		return path
+	}
+	path, err := os.Executable()
+	util.HandleErr(err, "Failed to determine the devoops executable: %v", err)
+	return path
+}
+
+func init() {
+	eksCmd.AddCommand(eksSyncCmd)
+	eksSyncCmd.Flags().StringSliceVarP(&eksSyncProfiles, "profile", "p", []string{}, "AWS profiles to search for clusters (default $AWS_PROFILE)")
+	eksSyncCmd.Flags().BoolVar(&eksSyncAllProfiles, "all-profiles", false, "Search all profiles defined in ~/.aws/credentials")
+	eksSyncCmd.Flags().StringSliceVar(&eksSyncRegions, "region", []string{}, "AWS regions to search for clusters (default the region of the profile)")
+	eksSyncCmd.Flags().BoolVar(&eksSyncDryRun, "dry-run", false, "Show the changed entries and their fields without writing the kube config")
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/adpg24/devoops/aws"
+	"github.com/adpg24/devoops/util"
+	"github.com/go-ini/ini"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+)
+
+var (
+	eksTokenClusterName string
+	eksTokenProfile     string
+	eksTokenRegion      string
+	eksTokenNoCache     bool
+)
+
+// eksTokenCmd represents the eks token command
+var eksTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Print an EKS bearer token as kubectl ExecCredential",
+	Long: `Print an EKS bearer token as a client.authentication.k8s.io/v1beta1 ExecCredential.
+
+The token is meant to be used by kubectl as exec credential plugin, see 'devoops eks sync'.
+Tokens are cached until shortly before they expire. Expired short-term credentials created
+with 'devoops login' are renewed and MFA codes for assumed roles are asked on stderr.`,
+	Run: printEksToken,
+}
+
+func printEksToken(cmd *cobra.Command, args []string) {
+	profile := eksTokenProfile
+	if profile == "" {
+		profile = defaultAwsProfile()
+	}
+
+	cachePath, err := aws.EksTokenCachePath(profile, eksTokenRegion, eksTokenClusterName)
+	util.HandleErr(err, "Failed to retrieve use home dir: %v", err)
+
+	var token *aws.EksToken
+	if !eksTokenNoCache {
+		token = aws.LoadCachedEksToken(cachePath)
+	}
+
+	if token == nil {
+		renewExpiredSession(profile)
+
+		cfg, err := aws.GetAwsConfig(&aws.AwsConfig{Profile: profile, Region: eksTokenRegion, MfaTokenProvider: promptMfaCode})
+		util.HandleErr(err, "❌ Failed to load AWS config: %v", err)
+		if cfg.Region == "" {
+			cfg.Region = region
+		}
+
+		token, err = aws.GetEksToken(cfg, eksTokenClusterName)
+		util.HandleErr(err, "❌ Failed to create a token for cluster %s: %v", eksTokenClusterName, err)
+
+		if err := aws.SaveCachedEksToken(cachePath, token); err != nil {
+			log.Printf("⚠ Failed to cache the token in %s: %v", cachePath, err)
+		}
+	}
+
+	expiration := metav1.NewTime(token.Expiration)
+	credential := clientauthv1beta1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{APIVersion: execApiVersion, Kind: "ExecCredential"},
+		Status:   &clientauthv1beta1.ExecCredentialStatus{Token: token.Token, ExpirationTimestamp: &expiration},
+	}
+	err = json.NewEncoder(os.Stdout).Encode(credential)
+	util.HandleErr(err, "Failed to write the ExecCredential: %v", err)
+}
+
+// renewExpiredSession runs the MFA login when the short-term credentials that
+// 'devoops login' created for the profile have expired.
+func renewExpiredSession(profile string) {
+	credFile, err := ini.Load(awsCredPath)
+	if err != nil {
+		return
+	}
+
+	expiration, ok := sessionExpiration(credFile, profile)
+	if ok && expiration.After(time.Now()) {
+		return
+	}
+	// profiles with static credentials are not managed by login
+	if !ok && credFile.HasSection(profile) {
+		return
+	}
+	if !credFile.HasSection(profile + longTermSuffix) {
+		return
+	}
+
+	log.Printf("ℹ The credentials of profile %s have expired, logging in again", profile)
+	mfaLogin(awsCredPath, credFile, profile, survey.WithStdio(os.Stdin, os.Stderr, os.Stderr))
+}
+
+// promptMfaCode asks for an MFA code on stderr, stdout is reserved for the ExecCredential.
+func promptMfaCode() (string, error) {
+	var code string
+	err := survey.AskOne(
+		&survey.Input{Message: "Please enter the MFA code to assume the role:"},
+		&code,
+		survey.WithValidator(survey.ComposeValidators(survey.MinLength(6), survey.MaxLength(6), survey.Required)),
+		survey.WithStdio(os.Stdin, os.Stderr, os.Stderr),
+	)
+	return code, err
+}
+
+func init() {
+	eksCmd.AddCommand(eksTokenCmd)
+	eksTokenCmd.Flags().StringVar(&eksTokenClusterName, "cluster-name", "", "Name of the EKS cluster")
+	eksTokenCmd.Flags().StringVarP(&eksTokenProfile, "profile", "p", "", "AWS profile used to create the token (default $AWS_PROFILE)")
+	eksTokenCmd.Flags().StringVar(&eksTokenRegion, "region", "", "AWS region of the cluster (default the region of the profile)")
+	eksTokenCmd.Flags().BoolVar(&eksTokenNoCache, "no-cache", false, "Ignore the cached token")
+	eksTokenCmd.MarkFlagRequired("cluster-name")
+}
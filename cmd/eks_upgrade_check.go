@@ -0,0 +1,296 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adpg24/devoops/aws"
+	"github.com/adpg24/devoops/kube"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+)
+
+const fargateProfileLabel string = "eks.amazonaws.com/fargate-profile"
+
+var (
+	upgradeCheckCluster string
+	upgradeCheckProfile string
+	upgradeCheckRegion  string
+	upgradeCheckContext string
+	upgradeCheckTimeout time.Duration
+)
+
+// eksUpgradeCheckCmd represents the eks upgrade-check command
+var eksUpgradeCheckCmd = &cobra.Command{
+	Use:   "upgrade-check",
+	Short: "Check if an EKS cluster is ready to upgrade to the next Kubernetes minor",
+	Long: `Check if an EKS cluster is ready to upgrade to the next Kubernetes minor.
+
+The report shows the version of the control plane, of each managed node group and of the nodes
+of each Fargate profile, the add-ons with available upgrades and whether their version works on
+the next minor, and the objects last applied with an API that the next minor removes.
+
+Removed APIs are looked up in a built-in deprecation table, the served API groups and the
+last-applied manifests are read with the kube context of the cluster.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := aws.GetAwsConfig(&aws.AwsConfig{Profile: upgradeCheckProfile, Region: upgradeCheckRegion})
+		if err != nil {
+			log.Fatalf("❌ Failed to load AWS config: %v", err)
+		}
+		_eks := aws.EksService{Client: eks.NewFromConfig(*cfg)}
+		cluster, err := _eks.DescribeCluster(upgradeCheckCluster)
+		if err != nil {
+			log.Fatalf("❌ Failed to describe EKS cluster %s: %v", upgradeCheckCluster, err)
+		}
+		current := awssdk.ToString(cluster.Version)
+		next, err := kube.NextMinor(current)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+
+		fmt.Printf("Cluster:       %s\n", *cluster.Arn)
+		fmt.Printf("Control plane: %s (%s)\n", current, awssdk.ToString(cluster.PlatformVersion))
+		fmt.Printf("Next version:  %s\n", next)
+
+		kubeConfig := kube.NewKubeConfig("")
+		kubeContext := upgradeCheckContext
+		if kubeContext == "" {
+			kubeContext = eksClusterContext(kubeConfig, *cluster.Arn)
+		}
+		var client kubernetes.Interface
+		var metadataClient metadata.Interface
+		if kubeContext == "" {
+			log.Printf("⚠ No kube context for cluster %s, Fargate nodes and removed APIs are not checked, run 'devoops eks sync' or use --context", *cluster.Arn)
+		} else {
+			config, err := kubeConfig.ClientConfig(kubeContext).ClientConfig()
+			if err != nil {
+				log.Fatalf("❌ Failed to build the config of context %s: %v", kubeContext, err)
+			}
+			if client, err = kubernetes.NewForConfig(config); err != nil {
+				log.Fatalf("❌ Failed to create a client for context %s: %v", kubeContext, err)
+			}
+			if metadataClient, err = metadata.NewForConfig(config); err != nil {
+				log.Fatalf("❌ Failed to create a client for context %s: %v", kubeContext, err)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), upgradeCheckTimeout)
+		defer cancel()
+
+		printNodegroupVersions(_eks, *cluster.Name, current)
+		if client != nil {
+			printFargateVersions(ctx, _eks, client, *cluster.Name, current)
+		}
+		printAddonVersions(_eks, *cluster.Name, current, next)
+		if client != nil {
+			printRemovedApis(ctx, client, metadataClient, next)
+		}
+	},
+}
+
+// versionMark shows whether the minor of a node version matches the control plane.
+func versionMark(nodeVersion string, controlPlane string) string {
+	v, err := version.ParseGeneric(nodeVersion)
+	if err != nil {
+		return "?"
+	}
+	c, err := version.ParseGeneric(controlPlane)
+	if err != nil || v.Major() != c.Major() || v.Minor() != c.Minor() {
+		return "⚠ behind"
+	}
+	return "✅"
+}
+
+func printNodegroupVersions(_eks aws.EksService, cluster string, controlPlane string) {
+	nodegroups, err := _eks.ListNodegroups(cluster)
+	if err != nil {
+		log.Printf("⚠ Failed to list the node groups of %s: %v", cluster, err)
+		return
+	}
+	fmt.Println("\nManaged node groups:")
+	if len(nodegroups) == 0 {
+		fmt.Println("  none")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE GROUP\tVERSION\tRELEASE\tSTATUS\tUP TO DATE")
+	for _, name := range nodegroups {
+		nodegroup, err := _eks.DescribeNodegroup(cluster, name)
+		if err != nil {
+			log.Printf("⚠ Failed to describe node group %s: %v", name, err)
+			continue
+		}
+		nodeVersion := awssdk.ToString(nodegroup.Version)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, nodeVersion, awssdk.ToString(nodegroup.ReleaseVersion), nodegroup.Status, versionMark(nodeVersion, controlPlane))
+	}
+	w.Flush()
+}
+
+// printFargateVersions shows the kubelet versions of the nodes of each Fargate profile, Fargate
+// starts pods with the version of the control plane so older nodes need their pods restarted.
+func printFargateVersions(ctx context.Context, _eks aws.EksService, client kubernetes.Interface, cluster string, controlPlane string) {
+	profiles, err := _eks.ListFargateProfiles(cluster)
+	if err != nil {
+		log.Printf("⚠ Failed to list the Fargate profiles of %s: %v", cluster, err)
+		return
+	}
+	if len(profiles) == 0 {
+		return
+	}
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: fargateProfileLabel})
+	if err != nil {
+		log.Printf("⚠ Failed to list the Fargate nodes: %v", err)
+		return
+	}
+	versions := map[string][]string{}
+	for _, node := range nodes.Items {
+		profile, kubelet := node.Labels[fargateProfileLabel], node.Status.NodeInfo.KubeletVersion
+		if !slices.Contains(versions[profile], kubelet) {
+			versions[profile] = append(versions[profile], kubelet)
+		}
+	}
+
+	fmt.Println("\nFargate profiles:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROFILE\tNODE VERSIONS\tUP TO DATE")
+	for _, profile := range profiles {
+		upToDate := "✅"
+		for _, kubelet := range versions[profile] {
+			if mark := versionMark(kubelet, controlPlane); mark != "✅" {
+				upToDate = mark
+			}
+		}
+		slices.Sort(versions[profile])
+		nodeVersions := strings.Join(versions[profile], ", ")
+		if nodeVersions == "" {
+			nodeVersions = "no nodes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", profile, nodeVersions, upToDate)
+	}
+	w.Flush()
+}
+
+func printAddonVersions(_eks aws.EksService, cluster string, current string, next string) {
+	addons, err := _eks.ListAddons(cluster)
+	if err != nil {
+		log.Printf("⚠ Failed to list the add-ons of %s: %v", cluster, err)
+		return
+	}
+	fmt.Println("\nAdd-ons:")
+	if len(addons) == 0 {
+		fmt.Println("  none")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ADD-ON\tVERSION\tLATEST\tDEFAULT ON %s\tWORKS ON %s\n", next, next)
+	for _, name := range addons {
+		addon, err := _eks.DescribeAddon(cluster, name)
+		if err != nil {
+			log.Printf("⚠ Failed to describe add-on %s: %v", name, err)
+			continue
+		}
+		installed := awssdk.ToString(addon.AddonVersion)
+		currentVersions, err := _eks.DescribeAddonVersions(name, current)
+		if err != nil {
+			log.Printf("⚠ Failed to list the versions of add-on %s: %v", name, err)
+			continue
+		}
+		nextVersions, err := _eks.DescribeAddonVersions(name, next)
+		if err != nil {
+			log.Printf("⚠ Failed to list the versions of add-on %s: %v", name, err)
+			continue
+		}
+
+		latest := latestAddonVersion(currentVersions)
+		if latest == installed {
+			latest = "✅"
+		}
+		nextDefault := "-"
+		if len(nextVersions) > 0 {
+			nextDefault = nextVersions[0]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, installed, latest, nextDefault, checkMark(slices.Contains(nextVersions, installed)))
+	}
+	w.Flush()
+}
+
+// latestAddonVersion returns the highest of the add-on versions, e.g. v1.18.1-eksbuild.3.
+func latestAddonVersion(versions []string) string {
+	latest, latestVersion := "-", (*version.Version)(nil)
+	for _, v := range versions {
+		parsed, err := version.ParseSemantic(v)
+		if err != nil {
+			continue
+		}
+		if latestVersion == nil || latestVersion.LessThan(parsed) {
+			latest, latestVersion = v, parsed
+		}
+	}
+	return latest
+}
+
+func printRemovedApis(ctx context.Context, client kubernetes.Interface, metadataClient metadata.Interface, next string) {
+	removed := kube.ApisRemovedIn(next)
+	fmt.Printf("\nAPIs removed in %s:\n", next)
+	if len(removed) == 0 {
+		fmt.Println("  none")
+		return
+	}
+	served, usages, err := kube.FindRemovedApis(ctx, client.Discovery(), metadataClient, removed)
+	if err != nil {
+		log.Printf("⚠ Failed to look for removed APIs: %v", err)
+		return
+	}
+	for _, api := range removed {
+		state := "not served"
+		if slices.Contains(served, api) {
+			state = "still served"
+		}
+		replacement := api.Replacement
+		if replacement == "" {
+			replacement = "no replacement"
+		}
+		fmt.Printf("  %s %s (%s), use %s\n", api.GroupVersion, api.Kind, state, replacement)
+	}
+
+	if len(usages) == 0 {
+		fmt.Println("\n✅ No objects were last applied with a removed API")
+		return
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAMESPACE\tNAME\tAPI\tREPLACEMENT")
+	for _, usage := range usages {
+		namespace := usage.Namespace
+		if namespace == "" {
+			namespace = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", usage.Api.Kind, namespace, usage.Name, usage.Api.GroupVersion, usage.Api.Replacement)
+	}
+	w.Flush()
+}
+
+func init() {
+	eksCmd.AddCommand(eksUpgradeCheckCmd)
+	eksUpgradeCheckCmd.Flags().StringVar(&upgradeCheckCluster, "cluster", "", "Name of the EKS cluster")
+	eksUpgradeCheckCmd.Flags().StringVarP(&upgradeCheckProfile, "profile", "p", "", "AWS profile of the cluster (default $AWS_PROFILE)")
+	eksUpgradeCheckCmd.Flags().StringVar(&upgradeCheckRegion, "region", "", "AWS region of the cluster (default the region of the profile)")
+	eksUpgradeCheckCmd.Flags().StringVar(&upgradeCheckContext, "context", "", "Kube context of the cluster (default the context pointing to the cluster)")
+	eksUpgradeCheckCmd.Flags().DurationVar(&upgradeCheckTimeout, "timeout", 2*time.Minute, "Timeout to read the cluster")
+	eksUpgradeCheckCmd.MarkFlagRequired("cluster")
+}
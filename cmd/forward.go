@@ -0,0 +1,110 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/adpg24/devoops/config"
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+)
+
+// forwardCmd represents the forward command
+var forwardCmd = &cobra.Command{
+	GroupID: "contextGroup",
+	Use:     "forward <preset>",
+	Short:   "Run a named set of port-forwards from the devoops config",
+	Long: `Run a named set of port-forwards from the forwards section of the devoops config concurrently.
+
+Every forward targets a service or a pod, by name or label selector, in the context and namespace of
+the forward or the current ones. When a pod is replaced, e.g. during a rollout, the forward reconnects
+to a new pod. When a forward cannot be set up, e.g. its local port is in use, all forwards stop.
+Stop all forwards with Ctrl-C.`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		devoopsConfig, err := config.Load()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var presets []string
+		for name := range devoopsConfig.Forwards {
+			presets = append(presets, name)
+		}
+		slices.Sort(presets)
+		return presets, cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		devoopsConfig, err := config.Load()
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		forwards, ok := devoopsConfig.Forwards[args[0]]
+		if !ok || len(forwards) == 0 {
+			log.Fatalf("❌ No forwards named %s in %s", args[0], config.ConfigPath())
+		}
+		kubeConfig := kube.NewKubeConfig("")
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		var failed atomic.Bool
+		for _, forward := range forwards {
+			if (forward.Service == "") == (forward.Pod == "") || forward.Local == 0 || forward.Remote == 0 {
+				log.Fatalf("❌ Invalid forward %+v, it needs a service or a pod, a local and a remote port", forward)
+			}
+
+			clientConfig := kubeConfig.ClientConfig(forward.Context)
+			restConfig, err := clientConfig.ClientConfig()
+			if err != nil {
+				log.Fatalf("❌ Failed to load the client config of context %q: %v", forward.Context, err)
+			}
+			client, err := kube.GetClientForContext(kubeConfig, forward.Context)
+			if err != nil {
+				log.Fatalf("❌ Failed to create a client for context %q: %v", forward.Context, err)
+			}
+			namespace := forward.Namespace
+			if namespace == "" {
+				namespace, _, _ = clientConfig.Namespace()
+			}
+
+			target := kube.ForwardTarget{
+				Namespace:  namespace,
+				Service:    strings.TrimPrefix(forward.Service, "service/"),
+				Pod:        strings.TrimPrefix(forward.Pod, "pod/"),
+				LocalPort:  forward.Local,
+				RemotePort: forward.Remote,
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := kube.Forward(ctx, restConfig, client, target, os.Stderr); err != nil {
+					log.Printf("❌ %s: %v, stopping all forwards", target, err)
+					failed.Store(true)
+					cancel()
+				}
+			}()
+		}
+
+		wg.Wait()
+		if failed.Load() {
+			os.Exit(1)
+		}
+		log.Println("ℹ Stopped all forwards")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(forwardCmd)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"log"
+	"strings"
+
+	"github.com/adpg24/devoops/aws"
+	"github.com/adpg24/devoops/guard"
+	"github.com/adpg24/devoops/kube"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+)
+
+var yesIMeanProd bool
+
+func kubeContextTarget(kubeConfig *kube.KubeConfig, context string) guard.Target {
+	target := guard.Target{Kind: guard.KubeContext, Name: context, Account: eksAccount(context)}
+	if c, ok := kubeConfig.Config.Contexts[context]; ok && target.Account == "" {
+		target.Account = eksAccount(c.Cluster)
+	}
+	return target
+}
+
+// awsProfileTarget returns the target of the profile, the account is looked up with
+// the credentials of cfg when it is not nil.
+func awsProfileTarget(profile string, cfg *awssdk.Config) guard.Target {
+	target := guard.Target{Kind: guard.AwsProfile, Name: profile}
+	if cfg != nil {
+		target.Account, _ = aws.GetAccountId(cfg)
+	}
+	return target
+}
+
+// checkGuard stops mutating commands that target a protected context or profile
+// unless --yes-i-mean-prod is given.
+func checkGuard(target guard.Target) {
+	if err := guard.Check(target, yesIMeanProd); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+}
+
+// eksAccount returns the AWS account of an EKS cluster ARN, or "" for other names.
+func eksAccount(name string) string {
+	_, account, _, _ := parseEksArn(name)
+	return account
+}
+
+// parseEksArn returns the region, account and name of an EKS cluster ARN, ok is false for other names.
+func parseEksArn(name string) (string, string, string, bool) {
+	// arn:aws:eks:<region>:<account>:cluster/<name>
+	arn := strings.Split(name, ":")
+	if len(arn) != 6 || arn[2] != "eks" || !strings.HasPrefix(arn[5], "cluster/") {
+		return "", "", "", false
+	}
+	return arn[3], arn[4], strings.TrimPrefix(arn[5], "cluster/"), true
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&yesIMeanProd, guard.ConfirmFlag, false, "Confirm running a mutating command against a protected context or profile")
+}
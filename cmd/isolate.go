@@ -0,0 +1,45 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+)
+
+// isolateCmd represents the isolate command
+var isolateCmd = &cobra.Command{
+	GroupID: "contextGroup",
+	Use:     "isolate",
+	Short:   "Isolate the context of this shell",
+	Long: `Print the shell code that isolates the kube context of the current shell.
+
+Once isolated, switchContext writes the selected context and namespace to a small kube config
+of this shell instead of changing the current context in your kube config. Other shells and
+scripts keep using their own context. The file is removed when the shell exits.
+
+Add the following line to your ~/.bashrc or ~/.zshrc to isolate every shell:
+
+    eval "$(devoops isolate)"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		kube.CleanIsolatedConfigs()
+		kubeConfig := kube.NewKubeConfig("")
+
+		// a nested shell shares the kube configs of its parent but not its isolated config
+		var sharedPaths []string
+		for _, path := range kubeConfig.PathOptions.GetLoadingPrecedence() {
+			if !strings.HasPrefix(path, kube.IsolatedConfigDir()) {
+				sharedPaths = append(sharedPaths, path)
+			}
+		}
+		fmt.Print(kube.IsolatedShellInit(sharedPaths))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(isolateCmd)
+}
@@ -0,0 +1,33 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+)
+
+// kubeCmd represents the kube command
+var kubeCmd = &cobra.Command{
+	GroupID: "contextGroup",
+	Use:     "kube",
+	Short:   "Inspect the clusters of your kube contexts",
+}
+
+// kubeNamespace returns the namespace flag, "" for all namespaces, or the namespace
+// of the current context when the flag is not set.
+func kubeNamespace(kubeConfig *kube.KubeConfig, namespace string, allNamespaces bool) string {
+	if allNamespaces {
+		return ""
+	}
+	if namespace != "" {
+		return namespace
+	}
+	namespace, _, _ = kubeConfig.ClientConfig("").Namespace()
+	return namespace
+}
+
+func init() {
+	rootCmd.AddCommand(kubeCmd)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adpg24/devoops/aws"
+	"github.com/adpg24/devoops/kube"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/spf13/cobra"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	canIContext   string
+	canINamespace string
+	canIProfile   string
+	canITimeout   time.Duration
+)
+
+// kubeCanICmd represents the kube can-i command
+var kubeCanICmd = &cobra.Command{
+	Use:   "can-i",
+	Short: "Show what you are allowed to do in a namespace",
+	Long: `Show what the identity of a context is allowed to do in a namespace, as a matrix of the most
+common resources and the verbs get, list, create, patch and delete.
+
+The matrix is built with SelfSubjectAccessReviews, other rules found with a SelfSubjectRulesReview
+are listed below it. On EKS the IAM principal the credentials map to is shown, with the access entry
+or the aws-auth config map entry that maps it.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		kubeConfig := kube.NewKubeConfig("")
+		kubeContext := canIContext
+		if kubeContext == "" {
+			kubeContext = kubeConfig.GetCurrentContext()
+		}
+		client, err := kube.GetClientForContext(kubeConfig, kubeContext)
+		if err != nil {
+			log.Fatalf("❌ Failed to create a client for context %s: %v", kubeContext, err)
+		}
+		namespace := canINamespace
+		if namespace == "" {
+			namespace, _, _ = kubeConfig.ClientConfig(kubeContext).Namespace()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), canITimeout)
+		defer cancel()
+
+		user, err := kube.WhoAmI(ctx, client)
+		if err != nil {
+			log.Printf("⚠ Failed to find out who you are: %v", err)
+		} else {
+			fmt.Printf("User:      %s\n", user.Username)
+			fmt.Printf("Groups:    %s\n", strings.Join(user.Groups, ", "))
+			if contextEntry := kubeConfig.Config.Contexts[kubeContext]; contextEntry != nil {
+				if region, _, name, ok := parseEksArn(contextEntry.Cluster); ok {
+					printEksMapping(ctx, kubeConfig, kubeContext, client, region, name, user)
+				}
+			}
+		}
+		fmt.Printf("Namespace: %s\n\n", namespace)
+
+		matrix, err := kube.CanI(ctx, client, namespace, kube.AccessResources, kube.AccessVerbs)
+		if err != nil {
+			log.Fatalf("❌ Failed to review your access: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RESOURCE\t"+strings.ToUpper(strings.Join(kube.AccessVerbs, "\t")))
+		for _, access := range matrix {
+			row := access.Resource.String()
+			for _, verb := range kube.AccessVerbs {
+				row += "\t" + checkMark(access.Allowed[verb])
+			}
+			fmt.Fprintln(w, row)
+		}
+		w.Flush()
+
+		printOtherRules(ctx, client, namespace)
+	},
+}
+
+// printEksMapping shows the IAM principal of the user and whether an access entry
+// or the aws-auth config map maps it.
+func printEksMapping(ctx context.Context, kubeConfig *kube.KubeConfig, kubeContext string, client kubernetes.Interface, region string, cluster string, user *authenticationv1.UserInfo) {
+	// EKS adds the role ARN without the session as canonicalArn
+	principal := ""
+	if arns := user.Extra["canonicalArn"]; len(arns) > 0 {
+		principal = arns[0]
+	} else if arns := user.Extra["arn"]; len(arns) > 0 {
+		principal = arns[0]
+	}
+	if principal == "" {
+		return
+	}
+	fmt.Printf("IAM:       %s\n", principal)
+
+	profile := canIProfile
+	if profile == "" {
+		profile = eksContextProfile(kubeConfig, kubeContext)
+	}
+	cfg, err := aws.GetAwsConfig(&aws.AwsConfig{Profile: profile, Region: region})
+	if err != nil {
+		log.Printf("⚠ Failed to load AWS config: %v", err)
+		return
+	}
+	_eks := aws.EksService{Client: eks.NewFromConfig(*cfg)}
+
+	mappings := []string{}
+	var notFound *ekstypes.ResourceNotFoundException
+	entry, err := _eks.DescribeAccessEntry(cluster, principal)
+	if err != nil && !errors.As(err, &notFound) {
+		log.Printf("⚠ Failed to look up the access entry of %s: %v", principal, err)
+	}
+	if err == nil {
+		mapping := fmt.Sprintf("access entry (username %s", awssdk.ToString(entry.Username))
+		if len(entry.KubernetesGroups) > 0 {
+			mapping += ", groups " + strings.Join(entry.KubernetesGroups, ", ")
+		}
+		policies, err := _eks.ListAssociatedAccessPolicies(cluster, principal)
+		if err != nil {
+			log.Printf("⚠ Failed to list the access policies of %s: %v", principal, err)
+		}
+		for _, policy := range policies {
+			name := (*policy.PolicyArn)[strings.LastIndex(*policy.PolicyArn, "/")+1:]
+			scope := string(policy.AccessScope.Type)
+			if len(policy.AccessScope.Namespaces) > 0 {
+				scope += " " + strings.Join(policy.AccessScope.Namespaces, ", ")
+			}
+			mapping += fmt.Sprintf(", policy %s on %s", name, scope)
+		}
+		mappings = append(mappings, mapping+")")
+	}
+
+	awsAuth, err := kube.GetAwsAuth(ctx, client)
+	if err != nil {
+		log.Printf("⚠ Failed to read the aws-auth config map: %v", err)
+	} else if awsAuth != nil {
+		if mapping := awsAuth.Find(principal); mapping != nil {
+			mappings = append(mappings, fmt.Sprintf("aws-auth config map (username %s, groups %s)", mapping.Username, strings.Join(mapping.Groups, ", ")))
+		}
+	}
+
+	if len(mappings) == 0 {
+		mappings = append(mappings, "no access entry or aws-auth entry, e.g. the creator of the cluster")
+	}
+	fmt.Printf("Mapped by: %s\n", strings.Join(mappings, "\n           "))
+}
+
+// printOtherRules lists the rules of the SelfSubjectRulesReview for resources that are not in the matrix.
+func printOtherRules(ctx context.Context, client kubernetes.Interface, namespace string) {
+	rules, err := kube.ListRules(ctx, client, namespace)
+	if err != nil {
+		log.Printf("⚠ Failed to list your rules: %v", err)
+		return
+	}
+
+	inMatrix := map[string]bool{}
+	for _, resource := range kube.AccessResources {
+		inMatrix[resource.Resource] = true
+	}
+	var other []string
+	for _, rule := range rules.ResourceRules {
+		var resources []string
+		for _, resource := range rule.Resources {
+			if !inMatrix[strings.Split(resource, "/")[0]] {
+				resources = append(resources, resource)
+			}
+		}
+		if len(resources) > 0 {
+			other = append(other, fmt.Sprintf("  %s: %s", strings.Join(resources, ", "), strings.Join(rule.Verbs, ", ")))
+		}
+	}
+
+	if len(other) > 0 {
+		fmt.Println("\nOther rules:")
+		fmt.Println(strings.Join(other, "\n"))
+	}
+	if rules.Incomplete {
+		fmt.Println("\nℹ The API server reports the rules as incomplete, the matrix above is authoritative.")
+	}
+}
+
+func init() {
+	kubeCmd.AddCommand(kubeCanICmd)
+	kubeCanICmd.Flags().StringVar(&canIContext, "context", "", "Context to check (default the current context)")
+	kubeCanICmd.Flags().StringVarP(&canINamespace, "namespace", "n", "", "Namespace to check (default the namespace of the context)")
+	kubeCanICmd.Flags().StringVarP(&canIProfile, "profile", "p", "", "AWS profile to look up the EKS access entry with (default the profile of the context)")
+	kubeCanICmd.Flags().DurationVar(&canITimeout, "timeout", 30*time.Second, "Timeout to review the access")
+}
@@ -0,0 +1,98 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkAllContexts bool
+	checkTimeout     time.Duration
+)
+
+// kubeCheckCmd represents the kube check command
+var kubeCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the health of your contexts",
+	Long: `Check if the API servers of your contexts are reachable and accept your credentials.
+
+The report shows the server version and how many minor versions it differs from devoops,
+whether authentication works, and when the client certificate or token and the CA expire. For
+the exec plugin of devoops eks token the expiry of the cached token is shown, for other exec
+plugins "exec".
+All contexts are checked at the same time.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		kubeConfig := kube.NewKubeConfig("")
+		contexts := []string{kubeConfig.GetCurrentContext()}
+		if checkAllContexts {
+			contexts = kubeConfig.GetContexts()
+		}
+
+		results := make([]*kube.ContextHealth, len(contexts))
+		var wg sync.WaitGroup
+		for i, context := range contexts {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = kubeConfig.CheckContext(context, checkTimeout)
+			}()
+		}
+		wg.Wait()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CONTEXT\tREACHABLE\tVERSION\tSKEW\tAUTH\tCREDENTIALS\tCA")
+		failed := false
+		for _, health := range results {
+			version, skew, auth := "-", "-", "-"
+			if health.Reachable() {
+				auth = checkMark(health.Authenticated())
+			}
+			if health.ServerVersion != "" {
+				version = health.ServerVersion
+				skew = fmt.Sprintf("%+d", health.VersionSkew)
+			}
+			credentials := kube.FormatExpiry(health.CredentialExpiry)
+			if health.CredentialExpiry == nil && health.ExecCredential {
+				credentials = "exec"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", health.Context, checkMark(health.Reachable()), version, skew, auth,
+				credentials, kube.FormatExpiry(health.CaExpiry))
+			failed = failed || !health.Authenticated()
+		}
+		w.Flush()
+
+		for _, health := range results {
+			if health.Error != nil {
+				fmt.Printf("\n%s: %v", health.Context, health.Error)
+			} else if health.AuthError != nil {
+				fmt.Printf("\n%s: %v", health.Context, health.AuthError)
+			}
+		}
+		if failed {
+			fmt.Println()
+			os.Exit(1)
+		}
+	},
+}
+
+func checkMark(ok bool) string {
+	if ok {
+		return "✔"
+	}
+	return "❌"
+}
+
+func init() {
+	kubeCmd.AddCommand(kubeCheckCmd)
+	kubeCheckCmd.Flags().BoolVarP(&checkAllContexts, "all-contexts", "A", false, "Check all contexts instead of the current context")
+	kubeCheckCmd.Flags().DurationVar(&checkTimeout, "timeout", 5*time.Second, "Time to wait for each API server")
+}
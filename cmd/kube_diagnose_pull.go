@@ -0,0 +1,299 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/adpg24/devoops/aws"
+	"github.com/adpg24/devoops/kube"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	ecrtypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// actions the node needs to pull an image from ECR
+var ecrPullActions = []string{"ecr:BatchGetImage", "ecr:GetDownloadUrlForLayer", "ecr:BatchCheckLayerAvailability"}
+
+var (
+	diagnosePullNamespace string
+	diagnosePullContainer string
+	diagnosePullProfile   string
+	diagnosePullRole      string
+)
+
+// pullCause is the reason an image can not be pulled and how to fix it
+type pullCause struct {
+	Cause string
+	Fix   string
+}
+
+// kubeDiagnosePullCmd represents the kube diagnose-pull command
+var kubeDiagnosePullCmd = &cobra.Command{
+	Use:   "diagnose-pull <pod>",
+	Short: "Find out why a pod can not pull its image from ECR",
+	Long: `Find out why a pod can not pull its image from ECR.
+
+The image of the container that fails to pull is checked step by step: whether the repository and
+the tag exist, whether the node role is allowed to pull from the repository (using IAM policy
+simulation), whether the repository policy allows the account of the cluster when the image is
+in another account, and whether the image is built for the architecture of the node. The first
+failing check is reported with a suggested fix.
+
+The node role is looked up from the EKS node group of the node, use --role when the nodes do not
+belong to a managed node group. The ECR and IAM checks use the credentials of --profile.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		kubeConfig := kube.NewKubeConfig("")
+		client := kube.GetClient(kubeConfig)
+		namespace := kubeNamespace(kubeConfig, diagnosePullNamespace, false)
+
+		pod, err := client.CoreV1().Pods(namespace).Get(context.Background(), args[0], metav1.GetOptions{})
+		if err != nil {
+			log.Fatalf("❌ Failed to get pod %s/%s: %v", namespace, args[0], err)
+		}
+
+		cause := diagnosePull(kubeConfig, client, pod)
+		if cause == nil {
+			message := lastPullError(client, pod)
+			if message == "" {
+				message = "none"
+			}
+			cause = &pullCause{
+				Cause: fmt.Sprintf("No ECR problem found. The last pull error was: %s", message),
+				Fix:   "Check that the node can reach ECR, e.g. through a NAT gateway or the ecr.api, ecr.dkr and s3 VPC endpoints.",
+			}
+		}
+		fmt.Printf("\nCause: %s\nFix:   %s\n", cause.Cause, cause.Fix)
+	},
+}
+
+// diagnosePull runs the checks in order and returns the first cause found, nil when all checks pass.
+func diagnosePull(kubeConfig *kube.KubeConfig, client kubernetes.Interface, pod *corev1.Pod) *pullCause {
+	image := pullImage(pod, diagnosePullContainer)
+	ecrImage, ok := aws.ParseEcrImage(image)
+	if !ok {
+		return &pullCause{
+			Cause: fmt.Sprintf("The image %s is not in ECR.", image),
+			Fix:   "Check the image name and the imagePullSecrets of the pod for the credentials of that registry.",
+		}
+	}
+	fmt.Printf("ℹ Image %s\n", image)
+
+	cfg, err := aws.GetAwsConfig(&aws.AwsConfig{Profile: diagnosePullProfile, Region: ecrImage.Region})
+	if err != nil {
+		log.Fatalf("❌ Failed to load AWS config: %v", err)
+	}
+	_ecr := aws.EcrService{Client: ecr.NewFromConfig(*cfg)}
+
+	var repositoryNotFound *ecrtypes.RepositoryNotFoundException
+	if _, err := _ecr.DescribeRepository(ecrImage.Account, ecrImage.Repository); errors.As(err, &repositoryNotFound) {
+		return &pullCause{
+			Cause: fmt.Sprintf("The repository %s does not exist in account %s in %s.", ecrImage.Repository, ecrImage.Account, ecrImage.Region),
+			Fix:   "Fix the repository in the image of the pod, or create the repository and push the image.",
+		}
+	} else if err != nil {
+		fmt.Printf("⚠ Could not check the repository: %v\n", err)
+	} else {
+		fmt.Printf("✔ Repository %s exists\n", ecrImage.Repository)
+	}
+
+	var imageNotFound *ecrtypes.ImageNotFoundException
+	if _, err := _ecr.DescribeImage(ecrImage.Account, ecrImage.Repository, ecrImage.ImageId()); errors.As(err, &imageNotFound) {
+		return &pullCause{
+			Cause: fmt.Sprintf("The image %s does not exist in repository %s.", ecrImage.Reference(), ecrImage.Repository),
+			Fix:   "Push the image, or deploy a tag that exists in the repository.",
+		}
+	} else if err != nil {
+		fmt.Printf("⚠ Could not check the image: %v\n", err)
+	} else {
+		fmt.Printf("✔ Image %s exists\n", ecrImage.Reference())
+	}
+
+	var node *corev1.Node
+	if pod.Spec.NodeName != "" {
+		node, err = client.CoreV1().Nodes().Get(context.Background(), pod.Spec.NodeName, metav1.GetOptions{})
+		if err != nil {
+			fmt.Printf("⚠ Could not get node %s: %v\n", pod.Spec.NodeName, err)
+			node = nil
+		}
+	}
+
+	role, roleKind := pullRole(kubeConfig, client, pod, node, cfg)
+	var roleArn arn.ARN
+	if role != "" {
+		if roleArn, err = arn.Parse(role); err != nil {
+			if diagnosePullRole != "" {
+				log.Fatalf("❌ Invalid role ARN %q, expected arn:aws:iam::<account>:role/<name>", role)
+			}
+			fmt.Printf("⚠ The %s %q is not a valid ARN, skipping its checks\n", roleKind, role)
+			role = ""
+		}
+	}
+	if role == "" {
+		fmt.Println("⚠ Could not find the node role, use --role to check its permissions")
+	} else {
+		denied, err := aws.SimulatePrincipalPolicy(cfg, role, ecrPullActions, ecrImage.RepositoryArn())
+		if err == nil {
+			var deniedToken []string
+			deniedToken, err = aws.SimulatePrincipalPolicy(cfg, role, []string{"ecr:GetAuthorizationToken"}, "*")
+			denied = append(denied, deniedToken...)
+		}
+		switch {
+		case err != nil:
+			fmt.Printf("⚠ Could not simulate the policies of %s %s: %v\n", roleKind, role, err)
+		case len(denied) > 0:
+			return &pullCause{
+				Cause: fmt.Sprintf("The %s %s is not allowed %s on repository %s.", roleKind, role, strings.Join(denied, ", "), ecrImage.Repository),
+				Fix:   "Attach the AmazonEC2ContainerRegistryReadOnly managed policy, or a policy allowing these actions, to the role.",
+			}
+		default:
+			fmt.Printf("✔ %s %s is allowed to pull\n", roleKind, role)
+		}
+	}
+
+	clusterAccount := eksAccount(currentCluster(kubeConfig))
+	if role != "" {
+		clusterAccount = roleArn.AccountID
+	}
+	if clusterAccount != "" && clusterAccount != ecrImage.Account {
+		policy, err := _ecr.GetRepositoryPolicy(ecrImage.Account, ecrImage.Repository)
+		allowed := false
+		if err == nil && policy != "" {
+			allowed, err = aws.PolicyAllows(policy, "ecr:BatchGetImage", clusterAccount, role)
+		}
+		switch {
+		case err != nil:
+			fmt.Printf("⚠ Could not check the repository policy: %v\n", err)
+		case !allowed:
+			return &pullCause{
+				Cause: fmt.Sprintf("The repository %s is in account %s and its policy does not allow the cluster account %s.", ecrImage.Repository, ecrImage.Account, clusterAccount),
+				Fix:   fmt.Sprintf("Add a statement to the repository policy allowing arn:aws:iam::%s:root %s.", clusterAccount, strings.Join(ecrPullActions, ", ")),
+			}
+		default:
+			fmt.Printf("✔ Repository policy allows account %s\n", clusterAccount)
+		}
+	}
+
+	if node != nil {
+		nodePlatform := node.Status.NodeInfo.OperatingSystem + "/" + node.Status.NodeInfo.Architecture
+		platforms, err := _ecr.GetImagePlatforms(ecrImage.Account, ecrImage.Repository, ecrImage.ImageId())
+		if err != nil {
+			fmt.Printf("⚠ Could not check the platforms of the image: %v\n", err)
+		} else {
+			var names []string
+			for _, platform := range platforms {
+				names = append(names, platform.String())
+			}
+			if !slices.ContainsFunc(platforms, func(platform aws.Platform) bool { return platform.OS+"/"+platform.Architecture == nodePlatform }) {
+				return &pullCause{
+					Cause: fmt.Sprintf("The image is built for %s but node %s is %s.", strings.Join(names, ", "), node.Name, nodePlatform),
+					Fix:   fmt.Sprintf("Build the image for %s, e.g. with docker buildx --platform, or schedule the pod on %s nodes with a nodeSelector on kubernetes.io/arch.", nodePlatform, strings.Join(names, ", ")),
+				}
+			}
+			fmt.Printf("✔ Image is built for %s\n", nodePlatform)
+		}
+	}
+	return nil
+}
+
+// pullImage returns the image of the container, or of the first container that fails to pull.
+func pullImage(pod *corev1.Pod, container string) string {
+	statuses := slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses)
+	for _, status := range statuses {
+		if container != "" {
+			if status.Name == container {
+				return status.Image
+			}
+			continue
+		}
+		if waiting := status.State.Waiting; waiting != nil && (waiting.Reason == "ImagePullBackOff" || waiting.Reason == "ErrImagePull") {
+			return status.Image
+		}
+	}
+
+	for _, c := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+		if container == "" || c.Name == container {
+			return c.Image
+		}
+	}
+	log.Fatalf("❌ Pod %s has no container %s", pod.Name, container)
+	return ""
+}
+
+// pullRole returns the role the image is pulled with, the role of the EKS node group of the node,
+// or the IRSA role of the pod when the node role is unknown.
+func pullRole(kubeConfig *kube.KubeConfig, client kubernetes.Interface, pod *corev1.Pod, node *corev1.Node, cfg *awssdk.Config) (string, string) {
+	if diagnosePullRole != "" {
+		return diagnosePullRole, "role"
+	}
+
+	clusterRegion, _, clusterName, ok := parseEksArn(currentCluster(kubeConfig))
+	if nodegroup := nodeGroup(node); nodegroup != "" && ok {
+		regionCfg := cfg.Copy()
+		regionCfg.Region = clusterRegion
+		_eks := aws.EksService{Client: eks.NewFromConfig(regionCfg)}
+		group, err := _eks.DescribeNodegroup(clusterName, nodegroup)
+		if err == nil && group.NodeRole != nil {
+			return *group.NodeRole, "node role"
+		}
+		fmt.Printf("⚠ Could not describe node group %s: %v\n", nodegroup, err)
+	}
+
+	serviceAccount, err := client.CoreV1().ServiceAccounts(pod.Namespace).Get(context.Background(), pod.Spec.ServiceAccountName, metav1.GetOptions{})
+	if err == nil && serviceAccount.Annotations["eks.amazonaws.com/role-arn"] != "" {
+		fmt.Println("ℹ The node role is unknown, checking the IRSA role of the pod instead")
+		return serviceAccount.Annotations["eks.amazonaws.com/role-arn"], "IRSA role"
+	}
+	return "", ""
+}
+
+// currentCluster returns the cluster of the current context, an ARN for EKS clusters.
+func currentCluster(kubeConfig *kube.KubeConfig) string {
+	if context, ok := kubeConfig.Config.Contexts[kubeConfig.GetCurrentContext()]; ok {
+		return context.Cluster
+	}
+	return ""
+}
+
+func nodeGroup(node *corev1.Node) string {
+	if node == nil {
+		return ""
+	}
+	return node.Labels["eks.amazonaws.com/nodegroup"]
+}
+
+// lastPullError returns the message of the most recent failed event of the pod.
+func lastPullError(client kubernetes.Interface, pod *corev1.Pod) string {
+	events, err := kube.ListPodEvents(client, pod.Namespace)
+	if err != nil {
+		return ""
+	}
+	podEvents := events[string(pod.UID)]
+	for i := len(podEvents) - 1; i >= 0; i-- {
+		if podEvents[i].Reason == "Failed" {
+			return podEvents[i].Message
+		}
+	}
+	return ""
+}
+
+func init() {
+	kubeCmd.AddCommand(kubeDiagnosePullCmd)
+	kubeDiagnosePullCmd.Flags().StringVarP(&diagnosePullNamespace, "namespace", "n", "", "Namespace of the pod (default the namespace of the current context)")
+	kubeDiagnosePullCmd.Flags().StringVarP(&diagnosePullContainer, "container", "c", "", "Container to diagnose (default the first container that fails to pull)")
+	kubeDiagnosePullCmd.Flags().StringVarP(&diagnosePullProfile, "profile", "p", "", "AWS profile to check ECR and IAM with (default $AWS_PROFILE)")
+	kubeDiagnosePullCmd.Flags().StringVar(&diagnosePullRole, "role", "", "ARN of the role the node pulls images with (default the role of the EKS node group)")
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kubeDiffFrom      string
+	kubeDiffTo        string
+	kubeDiffNamespace string
+	kubeDiffTimeout   time.Duration
+)
+
+// kubeDiffCmd represents the kube diff command
+var kubeDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the workloads of two contexts",
+	Long: `Compare the Deployments, StatefulSets and CronJobs of two contexts, e.g. before a release.
+
+Workloads that only exist in one of the contexts are listed first. For the workloads in both
+contexts the differences in image tags, replicas, resource requests and limits, env var names
+and the keys of the config maps they use are shown as <from> → <to>.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		kubeConfig := kube.NewKubeConfig("")
+		from := listContextWorkloads(kubeConfig, kubeDiffFrom)
+		to := listContextWorkloads(kubeConfig, kubeDiffTo)
+
+		onlyFrom, onlyTo, diffs := kube.DiffWorkloads(from, to)
+		if len(onlyFrom)+len(onlyTo)+len(diffs) == 0 {
+			log.Printf("ℹ The workloads of %s and %s are the same", kubeDiffFrom, kubeDiffTo)
+			return
+		}
+
+		printWorkloadKeys("Only in "+kubeDiffFrom, onlyFrom)
+		printWorkloadKeys("Only in "+kubeDiffTo, onlyTo)
+		if len(diffs) > 0 {
+			fmt.Printf("Different (%s → %s):\n", kubeDiffFrom, kubeDiffTo)
+			for _, diff := range diffs {
+				fmt.Printf("  %s\n", diff.Key)
+				for _, change := range diff.Changes {
+					fmt.Printf("    %s\n", change)
+				}
+			}
+		}
+	},
+}
+
+func listContextWorkloads(kubeConfig *kube.KubeConfig, kubeContext string) []kube.Workload {
+	client, err := kube.GetClientForContext(kubeConfig, kubeContext)
+	if err != nil {
+		log.Fatalf("❌ Failed to create a client for context %s: %v", kubeContext, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), kubeDiffTimeout)
+	defer cancel()
+
+	workloads, err := kube.ListWorkloads(ctx, client, kubeDiffNamespace)
+	if err != nil {
+		log.Fatalf("❌ Failed to list the workloads of context %s: %v", kubeContext, err)
+	}
+	return workloads
+}
+
+func printWorkloadKeys(title string, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	fmt.Printf("%s:\n", title)
+	for _, key := range keys {
+		fmt.Printf("  %s\n", key)
+	}
+}
+
+func init() {
+	kubeCmd.AddCommand(kubeDiffCmd)
+	kubeDiffCmd.Flags().StringVar(&kubeDiffFrom, "from", "", "Context to compare from, e.g. staging")
+	kubeDiffCmd.Flags().StringVar(&kubeDiffTo, "to", "", "Context to compare to, e.g. prod")
+	kubeDiffCmd.Flags().StringVarP(&kubeDiffNamespace, "namespace", "n", "", "Only compare this namespace (default all namespaces)")
+	kubeDiffCmd.Flags().DurationVar(&kubeDiffTimeout, "timeout", 30*time.Second, "Timeout to list the workloads of a context")
+	kubeDiffCmd.MarkFlagRequired("from")
+	kubeDiffCmd.MarkFlagRequired("to")
+}
@@ -0,0 +1,50 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// kubeconfigCmd represents the kubeconfig command
+var kubeconfigCmd = &cobra.Command{
+	GroupID: "contextGroup",
+	Use:     "kubeconfig",
+	Aliases: []string{"kc"},
+	Short:   "Clean up your kube config",
+	Long: `Delete, rename, import, export and prune the contexts in your kube config.
+
+Every change makes a timestamped backup of the kube config files next to them.`,
+}
+
+// writeKubeConfig prints the changes compared to before, backs up the kube config
+// files and writes the changes.
+func writeKubeConfig(kubeConfig *kube.KubeConfig, before *api.Config) {
+	changes := kube.Diff(before, kubeConfig.Config)
+	if len(changes) == 0 {
+		log.Println("ℹ Your kube config is up to date")
+		return
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+
+	backups, err := kubeConfig.Backup()
+	if err != nil {
+		log.Fatalf("❌ Failed to back up the kube config, nothing was changed: %v", err)
+	}
+	kubeConfig.Write()
+	for _, backup := range backups {
+		log.Printf("Backup written to %s", backup)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(kubeconfigCmd)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var exportOutput string
+
+// kubeconfigExportCmd represents the kubeconfig export command
+var kubeconfigExportCmd = &cobra.Command{
+	Use:   "export [context]",
+	Short: "Export a context as a self-contained kube config",
+	Long: `Export a context with its cluster and user as a self-contained kube config to share.
+
+Certificates and keys are embedded. Be careful, the file contains the credentials of the user.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		kubeConfig := kube.NewKubeConfig("")
+		config, err := kubeConfig.Export(args[0])
+		if err != nil {
+			log.Fatalf("❌ Failed to export %s: %v", args[0], err)
+		}
+
+		if exportOutput == "" {
+			content, err := clientcmd.Write(*config)
+			if err != nil {
+				log.Fatalf("❌ Failed to export %s: %v", args[0], err)
+			}
+			os.Stdout.Write(content)
+			return
+		}
+		if err := clientcmd.WriteToFile(*config, exportOutput); err != nil {
+			log.Fatalf("❌ Failed to write %s: %v", exportOutput, err)
+		}
+		log.Printf("Context %s exported to %s", args[0], exportOutput)
+	},
+}
+
+func init() {
+	kubeconfigCmd.AddCommand(kubeconfigExportCmd)
+	kubeconfigExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "File to write the kube config to (default stdout)")
+}
@@ -0,0 +1,48 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"log"
+	"slices"
+
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var importOnConflict string
+
+// kubeconfigImportCmd represents the kubeconfig import command
+var kubeconfigImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Merge another kube config into yours",
+	Long: `Merge the clusters, users and contexts of another kube config into yours.
+
+Entries that already exist with the same content are skipped. Entries that have the name of a
+different entry are skipped, overwritten or imported under a new name, see --on-conflict.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !slices.Contains([]string{kube.ConflictSkip, kube.ConflictOverwrite, kube.ConflictRename}, importOnConflict) {
+			log.Fatalf("❌ Unknown --on-conflict %q, use skip, overwrite or rename", importOnConflict)
+		}
+
+		other, err := clientcmd.LoadFromFile(args[0])
+		if err != nil {
+			log.Fatalf("❌ The config could not be loaded from %s: %v", args[0], err)
+		}
+
+		kubeConfig := kube.NewKubeConfig("")
+		before := kubeConfig.Config.DeepCopy()
+		for _, message := range kubeConfig.Import(other, importOnConflict) {
+			log.Printf("⚠ %s", message)
+		}
+		writeKubeConfig(kubeConfig, before)
+	},
+}
+
+func init() {
+	kubeconfigCmd.AddCommand(kubeconfigImportCmd)
+	kubeconfigImportCmd.Flags().StringVar(&importOnConflict, "on-conflict", kube.ConflictSkip, "What to do with entries that have the name of a different entry: skip, overwrite or rename")
+}
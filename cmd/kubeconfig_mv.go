@@ -0,0 +1,31 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+)
+
+// kubeconfigMvCmd represents the kubeconfig mv command
+var kubeconfigMvCmd = &cobra.Command{
+	Use:   "mv [context] [newName]",
+	Short: "Rename a context",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		kubeConfig := kube.NewKubeConfig("")
+		before := kubeConfig.Config.DeepCopy()
+
+		if err := kubeConfig.RenameContext(args[0], args[1]); err != nil {
+			log.Fatalf("❌ Failed to rename %s: %v", args[0], err)
+		}
+		writeKubeConfig(kubeConfig, before)
+	},
+}
+
+func init() {
+	kubeconfigCmd.AddCommand(kubeconfigMvCmd)
+}
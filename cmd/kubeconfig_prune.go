@@ -0,0 +1,72 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+)
+
+var pruneTimeout time.Duration
+
+// kubeconfigPruneCmd represents the kubeconfig prune command
+var kubeconfigPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete contexts whose API server does not respond",
+	Long: `Delete the contexts whose API server does not respond within the timeout.
+
+All contexts are checked at the same time, without credentials so expired sessions and
+credential plugins that prompt do not make a context look unreachable. Use kube check to
+test the credentials. The unreachable contexts are listed and only deleted after confirmation.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		kubeConfig := kube.NewKubeConfig("")
+		before := kubeConfig.Config.DeepCopy()
+		contexts := kubeConfig.GetContexts()
+
+		errs := make([]error, len(contexts))
+		var wg sync.WaitGroup
+		for i, context := range contexts {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = kubeConfig.Ping(context, pruneTimeout)
+			}()
+		}
+		wg.Wait()
+
+		var unreachable []string
+		for i, context := range contexts {
+			if errs[i] != nil {
+				unreachable = append(unreachable, context)
+				fmt.Printf("%s: %v\n", context, errs[i])
+			}
+		}
+		if len(unreachable) == 0 {
+			log.Println("ℹ All contexts are reachable")
+			return
+		}
+
+		confirmed := false
+		prompt := &survey.Confirm{Message: fmt.Sprintf("Delete the %d unreachable context(s)?", len(unreachable))}
+		if err := survey.AskOne(prompt, &confirmed); err != nil || !confirmed {
+			log.Fatalf("ℹ Alright then, keep your contexts!\n")
+		}
+
+		for _, context := range unreachable {
+			kubeConfig.DeleteContext(context)
+		}
+		writeKubeConfig(kubeConfig, before)
+	},
+}
+
+func init() {
+	kubeconfigCmd.AddCommand(kubeconfigPruneCmd)
+	kubeconfigPruneCmd.Flags().DurationVar(&pruneTimeout, "timeout", 5*time.Second, "Time to wait for an API server to respond")
+}
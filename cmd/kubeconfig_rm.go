@@ -0,0 +1,35 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+)
+
+// kubeconfigRmCmd represents the kubeconfig rm command
+var kubeconfigRmCmd = &cobra.Command{
+	Use:   "rm [context]...",
+	Short: "Delete contexts",
+	Long:  `Delete contexts together with their cluster and user when no other context uses them`,
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		kubeConfig := kube.NewKubeConfig("")
+		before := kubeConfig.Config.DeepCopy()
+
+		for _, context := range args {
+			if _, ok := kubeConfig.Config.Contexts[context]; !ok {
+				log.Fatalf("The context %s does not exist in the config", context)
+			}
+			kubeConfig.DeleteContext(context)
+		}
+		writeKubeConfig(kubeConfig, before)
+	},
+}
+
+func init() {
+	kubeconfigCmd.AddCommand(kubeconfigRmCmd)
+}
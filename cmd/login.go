@@ -64,8 +64,36 @@ func login(cmd *cobra.Command, args []string) {
 	credFile, err := ini.Load(awsCredPath)
 	util.HandleErr(err, "❌ Failed to load AWS config file %s", awsCredPath)
 
-	shortTermProfile = awsProfile
-	longTermProfile = fmt.Sprintf("%s%s", awsProfile, longTermSuffix)
+	// validate short term profile = [profile]
+	if expiration, ok := sessionExpiration(credFile, awsProfile); ok && expiration.After(time.Now()) {
+		log.Printf("ℹ You're still authenticated! Your credential will expire at %s.\n", expiration.Format("2006-01-02 15:04:05"))
+		os.Exit(0)
+	}
+
+	mfaLogin(awsCredPath, credFile, awsProfile)
+}
+
+// sessionExpiration returns the expiration of the short-term credentials that login
+// wrote for the profile, ok is false when the profile has no such credentials.
+func sessionExpiration(credFile *ini.File, profile string) (expiration time.Time, ok bool) {
+	shortTermConfig, err := credFile.GetSection(profile)
+	if err != nil || !shortTermConfig.HasKey("expiration") {
+		return time.Time{}, false
+	}
+
+	expirationkey := shortTermConfig.Key("expiration")
+	expiration, err = time.Parse("2006-01-02 15:04:05", expirationkey.String())
+	if err != nil {
+		log.Fatalf("❌ Expiration (%s) in profile \"%s\" is in the wrong format (2006-01-02 15:04:05)!\nError: %s\n", expirationkey.String(), profile, err.Error())
+	}
+	return expiration, true
+}
+
+// mfaLogin requests short-term credentials for the long-term profile [profile]-mfa and
+// saves them as [profile]. The survey options allow callers to prompt on another output.
+func mfaLogin(credPath string, credFile *ini.File, profile string, opts ...survey.AskOpt) {
+	shortTermProfile = profile
+	longTermProfile = fmt.Sprintf("%s%s", profile, longTermSuffix)
 
 	// validate long term profile = [profile]-mfa
 	if longTermCreds, err := credFile.GetSection(longTermProfile); err != nil {
@@ -86,19 +114,6 @@ func login(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	// validate short term profile = [profile]
-	if shortTermConfig, err := credFile.GetSection(shortTermProfile); err == nil {
-		currentTime := time.Now()
-		expirationkey := shortTermConfig.Key("expiration")
-
-		if expiration, err := time.Parse("2006-01-02 15:04:05", expirationkey.String()); err != nil {
-			log.Fatalf("❌ Expiration (%s) in profile \"%s\" is in the wrong format (2006-01-02 15:04:05)!\nError: %s\n", expirationkey.String(), shortTermProfile, err.Error())
-		} else if expiration.After(currentTime) {
-			log.Printf("ℹ You're still authenticated! Your credential will expire at %s.\n", expiration.Format("2006-01-02 15:04:05"))
-			os.Exit(0)
-		}
-	}
-
 	conf, err := aws.GetAwsConfig(&aws.AwsConfig{Region: region, Profile: longTermProfile})
 	util.HandleErr(err, "Failed to retrieve config: %v", err)
 
@@ -143,7 +158,7 @@ func login(cmd *cobra.Command, args []string) {
 		answers = mfaSurveyAnswer{MfaDevice: mfaDevice}
 	}
 
-	err = survey.Ask(qs, &answers)
+	err = survey.Ask(qs, &answers, opts...)
 	if err != nil {
 		if err.Error() == "interrupt" {
 			log.Fatalf("ℹ Alright then, keep your secrets! Exiting..\n")
@@ -166,8 +181,8 @@ func login(cmd *cobra.Command, args []string) {
 		"aws_session_token":     *session.Credentials.SessionToken,
 		"expiration":            session.Credentials.Expiration.Format("2006-01-02 15:04:05"),
 	}
-	err = util.AddProfileSection(awsCredPath, credFile, shortTermProfile, sectionKeys)
-	util.HandleErr(err, "Failed to add new profile/section to %s: %v", awsCredPath, err)
+	err = util.AddProfileSection(credPath, credFile, shortTermProfile, sectionKeys)
+	util.HandleErr(err, "Failed to add new profile/section to %s: %v", credPath, err)
 
 	log.Printf("The short-term credentials were successfully created for profile %s", shortTermProfile)
 }
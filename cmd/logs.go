@@ -0,0 +1,154 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	logsNamespace  string
+	logsContainer  string
+	logsSince      time.Duration
+	logsFollow     bool
+	logsGrep       string
+	logsJsonFields []string
+)
+
+var logColors = []string{"red", "green", "yellow", "blue", "magenta", "cyan"}
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	GroupID: "contextGroup",
+	Use:     "logs [selector|deployment]",
+	Short:   "Stream the logs of all pods matching a selector or deployment",
+	Long: `Stream the logs of all pods matching a label selector, e.g. app=api, or the selector of a deployment.
+
+Every line is prefixed with the pod and container it comes from. When following, pods that appear
+are picked up and pods that go away are no longer followed, so a deployment can be followed across
+rollouts. Use --grep to only show lines matching a regular expression and --json-field to only
+show JSON lines with a field of the given value, e.g. --json-field level=error.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		kubeConfig := kube.NewKubeConfig("")
+		client := kube.GetClient(kubeConfig)
+		namespace := kubeNamespace(kubeConfig, logsNamespace, false)
+
+		grep, err := regexp.Compile(logsGrep)
+		if err != nil {
+			log.Fatalf("❌ Invalid --grep: %v", err)
+		}
+		jsonFields := map[string]string{}
+		for _, field := range logsJsonFields {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				log.Fatalf("❌ Invalid --json-field %q, use key=value", field)
+			}
+			jsonFields[key] = value
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		lines := make(chan kube.LogLine)
+		errs := make(chan error, 1)
+		go func() {
+			errs <- kube.StreamLogs(ctx, client, kube.LogOptions{
+				Namespace: namespace,
+				Selector:  logsSelector(client, namespace, args[0]),
+				Container: logsContainer,
+				Since:     logsSince,
+				Follow:    logsFollow,
+			}, lines)
+		}()
+
+		for line := range lines {
+			if !grep.MatchString(line.Text) || !matchJsonFields(line.Text, jsonFields) {
+				continue
+			}
+			prefix := fmt.Sprintf("[%s/%s]", line.Pod, line.Container)
+			fmt.Println(colorize(logColor(line.Pod), prefix), line.Text)
+		}
+		if err := <-errs; err != nil {
+			log.Fatalf("❌ Failed to stream the logs: %v", err)
+		}
+	},
+}
+
+// logsSelector returns the argument when it is a label selector, otherwise the
+// selector of the deployment with that name.
+func logsSelector(client kubernetes.Interface, namespace string, selectorOrDeployment string) string {
+	if strings.ContainsAny(selectorOrDeployment, "=,()!") || strings.Contains(selectorOrDeployment, " in ") {
+		return selectorOrDeployment
+	}
+
+	name := strings.TrimPrefix(strings.TrimPrefix(selectorOrDeployment, "deployment/"), "deploy/")
+	deployment, err := client.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		log.Fatalf("❌ Failed to get deployment %s/%s: %v", namespace, name, err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		log.Fatalf("❌ Invalid selector of deployment %s/%s: %v", namespace, name, err)
+	}
+	return selector.String()
+}
+
+// matchJsonFields reports whether the line is a JSON object with the given values,
+// nested fields are separated by dots.
+func matchJsonFields(line string, fields map[string]string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal([]byte(line), &object); err != nil {
+		return false
+	}
+	for key, expected := range fields {
+		var value any = object
+		for _, part := range strings.Split(key, ".") {
+			nested, ok := value.(map[string]any)
+			if !ok {
+				return false
+			}
+			value = nested[part]
+		}
+		if value == nil || fmt.Sprint(value) != expected {
+			return false
+		}
+	}
+	return true
+}
+
+// logColor gives every pod a stable color.
+func logColor(pod string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(pod))
+	return logColors[hash.Sum32()%uint32(len(logColors))]
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.Flags().StringVarP(&logsNamespace, "namespace", "n", "", "Namespace of the pods (default the namespace of the current context)")
+	logsCmd.Flags().StringVarP(&logsContainer, "container", "c", "", "Only show the logs of this container")
+	logsCmd.Flags().DurationVar(&logsSince, "since", 0, "Only show logs newer than this duration, e.g. 10m")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Follow the logs")
+	logsCmd.Flags().StringVar(&logsGrep, "grep", "", "Only show lines that match this regular expression")
+	logsCmd.Flags().StringArrayVar(&logsJsonFields, "json-field", []string{}, "Only show JSON lines with this field value, e.g. level=error")
+}
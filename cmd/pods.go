@@ -0,0 +1,123 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const podEventsShown int = 5
+
+var (
+	podsNamespace     string
+	podsAllNamespaces bool
+	podsSelector      string
+	podsProblems      bool
+)
+
+// podsCmd represents the pods command
+var podsCmd = &cobra.Command{
+	GroupID: "contextGroup",
+	Use:     "pods",
+	Aliases: []string{"po"},
+	Short:   "Show the pods of the current context, the most broken first",
+	Long: `Show the pods of the current context with their status, ready containers, restarts, age,
+node and image tags. The most broken pods are shown first.
+
+Use --problems to only show the pods that are in CrashLoopBackOff, ImagePullBackOff, OOMKilled,
+Pending or Evicted, with the reason of the last termination and their recent events.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		kubeConfig := kube.NewKubeConfig("")
+		client := kube.GetClient(kubeConfig)
+		namespace := kubeNamespace(kubeConfig, podsNamespace, podsAllNamespaces)
+
+		statuses, err := kube.ListPodStatus(client, namespace, podsSelector)
+		if err != nil {
+			log.Fatalf("❌ Failed to list the pods: %v", err)
+		}
+
+		if podsProblems {
+			var problems []*kube.PodStatus
+			for _, status := range statuses {
+				if status.Problem != "" {
+					problems = append(problems, status)
+				}
+			}
+			statuses = problems
+		}
+		if len(statuses) == 0 {
+			log.Println("ℹ No pods found")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		header := "NAME\tSTATUS\tREADY\tRESTARTS\tAGE\tNODE\tIMAGE"
+		if podsAllNamespaces {
+			header = "NAMESPACE\t" + header
+		}
+		if podsProblems {
+			header += "\tLAST TERMINATION"
+		}
+		fmt.Fprintln(w, header)
+
+		for _, status := range statuses {
+			pod := status.Pod
+			state := status.Problem
+			if state == "" {
+				state = string(pod.Status.Phase)
+			}
+
+			row := fmt.Sprintf("%s\t%s\t%d/%d\t%d\t%s\t%s\t%s", pod.Name, state, status.Ready, status.Total, status.Restarts,
+				duration.HumanDuration(time.Since(pod.CreationTimestamp.Time)), pod.Spec.NodeName, strings.Join(status.ImageTags(), ","))
+			if podsAllNamespaces {
+				row = pod.Namespace + "\t" + row
+			}
+			if podsProblems {
+				row += "\t" + status.LastTermination
+			}
+			fmt.Fprintln(w, row)
+		}
+		w.Flush()
+
+		if podsProblems {
+			printPodEvents(kubeConfig, namespace, statuses)
+		}
+	},
+}
+
+func printPodEvents(kubeConfig *kube.KubeConfig, namespace string, statuses []*kube.PodStatus) {
+	events, err := kube.ListPodEvents(kube.GetClient(kubeConfig), namespace)
+	if err != nil {
+		log.Printf("⚠ Failed to list the events: %v", err)
+		return
+	}
+
+	for _, status := range statuses {
+		podEvents := events[string(status.Pod.UID)]
+		if len(podEvents) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s/%s:\n", status.Pod.Namespace, status.Pod.Name)
+		for _, event := range podEvents[max(0, len(podEvents)-podEventsShown):] {
+			fmt.Printf("  %s ago\t%s\t%s\t%s\n", duration.HumanDuration(time.Since(kube.EventTime(&event))), event.Type, event.Reason, event.Message)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(podsCmd)
+	podsCmd.Flags().StringVarP(&podsNamespace, "namespace", "n", "", "Namespace of the pods (default the namespace of the current context)")
+	podsCmd.Flags().BoolVarP(&podsAllNamespaces, "all-namespaces", "A", false, "Show the pods of all namespaces")
+	podsCmd.Flags().StringVarP(&podsSelector, "selector", "l", "", "Label selector to filter the pods, e.g. app=api")
+	podsCmd.Flags().BoolVar(&podsProblems, "problems", false, "Only show broken pods with their last termination reason and recent events")
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/adpg24/devoops/kube"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	shellNamespace string
+	shellContainer string
+)
+
+// shellCommand starts bash when the container has it, otherwise sh
+var shellCommand = []string{"sh", "-c", "command -v bash >/dev/null && exec bash || exec sh"}
+
+// shellCmd represents the shell command
+var shellCmd = &cobra.Command{
+	GroupID: "contextGroup",
+	Use:     "shell [pod] [-- command...]",
+	Short:   "Open a shell in a container of a pod",
+	Long: `Open a shell in a container of a pod, like kubectl exec -it. The namespace, pod and container
+are chosen from a list unless they are given with --namespace, the pod argument and --container.
+
+bash is started when the container has it, otherwise sh. A command after -- is run instead.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		command := shellCommand
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			command = args[dash:]
+			args = args[:dash]
+		}
+		if len(args) > 1 {
+			log.Fatalf("❌ Expected at most one pod, got %v", args)
+		}
+
+		kubeConfig := kube.NewKubeConfig("")
+		client := kube.GetClient(kubeConfig)
+		restConfig, err := kubeConfig.ClientConfig("").ClientConfig()
+		if err != nil {
+			log.Fatalf("❌ Failed to load the client config: %v", err)
+		}
+
+		namespace := shellNamespace
+		if namespace == "" {
+			namespaces, err := client.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				log.Fatalf("❌ Failed to list the namespaces: %v", err)
+			}
+			var names []string
+			for _, namespace := range namespaces.Items {
+				names = append(names, namespace.Name)
+			}
+			namespace = askShell("Choose a namespace:", names, kubeNamespace(kubeConfig, "", false))
+		}
+
+		var pod string
+		if len(args) == 1 {
+			pod = args[0]
+		} else {
+			pods, err := client.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				log.Fatalf("❌ Failed to list the pods: %v", err)
+			}
+			var names []string
+			for _, pod := range pods.Items {
+				if pod.Status.Phase == corev1.PodRunning {
+					names = append(names, pod.Name)
+				}
+			}
+			if len(names) == 0 {
+				log.Fatalf("ℹ No running pods in namespace %s", namespace)
+			}
+			pod = askShell("Choose a pod:", names, "")
+		}
+
+		container := shellContainer
+		if container == "" {
+			running, err := client.CoreV1().Pods(namespace).Get(context.Background(), pod, metav1.GetOptions{})
+			if err != nil {
+				log.Fatalf("❌ Failed to get pod %s/%s: %v", namespace, pod, err)
+			}
+			var names []string
+			for _, container := range running.Spec.Containers {
+				names = append(names, container.Name)
+			}
+			container = names[0]
+			if len(names) > 1 {
+				container = askShell("Choose a container:", names, running.Annotations["kubectl.kubernetes.io/default-container"])
+			}
+		}
+
+		if err := kube.Exec(context.Background(), restConfig, client, namespace, pod, container, command); err != nil {
+			log.Fatalf("❌ Failed to open a shell in %s/%s: %v", pod, container, err)
+		}
+	},
+}
+
+// askShell lets the user choose one of the options, exiting when the prompt is interrupted.
+func askShell(message string, options []string, defaultOption string) string {
+	prompt := &survey.Select{Message: message, Options: options}
+	if slices.Contains(options, defaultOption) {
+		prompt.Default = defaultOption
+	}
+
+	var answer string
+	if err := survey.AskOne(prompt, &answer); err != nil {
+		if err.Error() == "interrupt" {
+			log.Fatalf("ℹ Alright then, no shell!\n")
+		}
+		log.Fatal(err.Error())
+	}
+	return answer
+}
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+	shellCmd.Flags().StringVarP(&shellNamespace, "namespace", "n", "", "Namespace of the pod (default choose from a list)")
+	shellCmd.Flags().StringVarP(&shellContainer, "container", "c", "", "Container to open the shell in (default choose from a list)")
+}
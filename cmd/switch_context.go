@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/adpg24/devoops/guard"
 	"github.com/adpg24/devoops/kube"
 	"log"
 
@@ -36,6 +37,9 @@ func runConfig(cmd *cobra.Command, args []string) {
 				Default: kubeConfig.GetCurrentContext(),
 				Message: "Choose a context:",
 				Options: contexts,
+				Description: func(value string, index int) string {
+					return kubeConfig.GetContextFile(value)
+				},
 			},
 		},
 	}
@@ -51,6 +55,9 @@ func runConfig(cmd *cobra.Command, args []string) {
 			log.Fatal(err.Error())
 		}
 	}
+	if err := guard.ConfirmSwitch(kubeContextTarget(kubeConfig, answers.Context)); err != nil {
+		log.Fatalf("ℹ Alright then, keep your contexts! %v\n", err)
+	}
 	log.Printf("Switched to context %s", answers.Context)
 	kubeConfig.SetCurrentContext(answers.Context)
 }
@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/adpg24/devoops/guard"
 	"github.com/adpg24/devoops/util"
 	"github.com/go-ini/ini"
 	"github.com/spf13/cobra"
@@ -69,7 +70,11 @@ func selectProfile(cmd *cobra.Command, args []string) {
 			log.Fatal(err.Error())
 		}
 	}
-	selectedProfile := strings.Split(answers.Profile, "/")[1]
+	selectedAccount, selectedProfile, _ := strings.Cut(answers.Profile, "/")
+	target := guard.Target{Kind: guard.AwsProfile, Name: selectedProfile, Account: selectedAccount}
+	if err := guard.ConfirmSwitch(target); err != nil {
+		log.Fatalf("ℹ Alright then, keep your profiles! %v\n", err)
+	}
 	exportCmd := fmt.Sprintf("export AWS_PROFILE=%s", selectedProfile)
 	err = util.CopyToClipboard(exportCmd)
 	if err != nil {
@@ -112,6 +117,31 @@ func retrieveProfiles() []AwsProfile {
 	return profiles
 }
 
+// defaultAwsProfile returns the profile selected with AWS_PROFILE or "default".
+func defaultAwsProfile() string {
+	if profile := os.Getenv("AWS_PROFILE"); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+// selectAwsProfiles returns the profiles, all short-term profiles of the credentials file
+// when all is set, or the default profile when none are given.
+func selectAwsProfiles(profiles []string, all bool) []string {
+	if all {
+		profiles = []string{}
+		for _, p := range retrieveProfiles() {
+			if !strings.HasSuffix(p.Name, longTermSuffix) && p.Name != "DEFAULT" {
+				profiles = append(profiles, p.Name)
+			}
+		}
+	}
+	if len(profiles) == 0 {
+		profiles = []string{defaultAwsProfile()}
+	}
+	return profiles
+}
+
 func init() {
 	rootCmd.AddCommand(awsProfileCmd)
 }
@@ -4,22 +4,65 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
 	"log"
+	"strings"
 
 	"github.com/adpg24/devoops/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
 	"github.com/spf13/cobra"
 )
 
-var repository string
+var (
+	repository     string
+	tagDigest      string
+	tagForce       bool
+	tagDryRun      bool
+	tagPlan        string
+	tagFromTag     string
+	tagToTags      []string
+	tagRepos       []string
+	tagConcurrency int
+)
 
 // tagCmd represents the tag command
 var tagCmd = &cobra.Command{
-	Use:   "tag [sourceTag] [newTag]",
+	Use:   "tag [sourceTag] [newTag] | --plan plan.yaml | --from-tag X --to-tag Y --repos glob",
 	Short: "Retag an AWS ECR image",
-	Long:  `Retag an AWS ECR image - No downloads necessary!`,
-	Run:   run,
-	Args:  cobra.MinimumNArgs(2),
+	Long: `Retag an AWS ECR image - No downloads necessary!
+
+The source is a tag, or a digest given with --digest. The manifest is tagged with the media type
+it was pushed with, so manifest lists and image indexes of multi-platform images keep working.
+The platform manifests of an index must exist in the repository before it is tagged.
+
+A tag that already points at the image is left alone, a tag that points at another image is
+only moved with --force.
+
+Many repositories are retagged at once with a plan file or with --from-tag, --to-tag and --repos.
+Repository globs are matched against the repositories of the registry, retags run at the same time
+and throttled calls are retried with backoff. A summary of the created, moved, skipped and failed
+retags is printed at the end, the exit code is 1 when a retag failed. A plan file looks like
+
+  retags:
+    - repos: ["team-*", "api"]
+      from: rc-1.4.2
+      to: ["1.4.2", "stable"]
+      force: true`,
+	Example: `  devoops tag -r api 1.4.2 stable
+  devoops tag -r api --digest sha256:0123... stable
+  devoops tag --from-tag rc-1.4.2 --to-tag 1.4.2,stable --repos 'team-*' --dry-run
+  devoops tag --plan release.yaml --concurrency 10`,
+	Run: run,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if tagPlan != "" || tagFromTag != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		if tagDigest != "" {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
 }
 
 func run(cmd *cobra.Command, args []string) {
@@ -28,21 +71,60 @@ func run(cmd *cobra.Command, args []string) {
 		log.Fatalf("Failed to load AWS config: %v", err)
 	}
 
-	client := ecr.NewFromConfig(*cfg)
+	if !tagDryRun {
+		checkGuard(awsProfileTarget(defaultAwsProfile(), cfg))
+	}
+	if tagPlan != "" || tagFromTag != "" {
+		runTagPlan(cfg)
+		return
+	}
+
+	source, imageId, newTag := repository+":"+args[0], types.ImageIdentifier{ImageTag: &args[0]}, args[len(args)-1]
+	if tagDigest != "" {
+		if !strings.HasPrefix(tagDigest, "sha256:") {
+			log.Fatalf("Invalid digest %q, expected sha256:...", tagDigest)
+		}
+		source, imageId = repository+"@"+tagDigest, types.ImageIdentifier{ImageDigest: &tagDigest}
+	}
+
+	client := ecr.NewFromConfig(*cfg, aws.WithThrottlingRetries)
 	_ecr := aws.EcrService{Client: client}
-	imageManifest, err := _ecr.GetImageManifest(repository, args[0])
+	result, err := _ecr.Retag(repository, imageId, newTag, tagForce, tagDryRun)
+	var conflict *aws.TagConflictError
+	if errors.As(err, &conflict) {
+		log.Fatalf("%s:%s already points at %s, use --force to move it", repository, newTag, conflict.Digest)
+	}
 	if err != nil {
-		log.Fatalf("Failed to retrieve image manifest for '%s:%s': %v", repository, args[0], err)
+		log.Fatalf("Failed to retag %s -> %s:%s: %v", source, repository, newTag, err)
 	}
 
-	_, err = _ecr.PutImage(repository, args[1], imageManifest)
-	if err != nil {
-		log.Fatalf("Failed to retag %s:%s -> %s:%s", repository, args[0], repository, args[1])
+	switch {
+	case tagDryRun && result.Changed:
+		log.Printf("Would point %s:%s at %s", repository, newTag, result.Digest)
+	case !result.Changed:
+		log.Printf("Tag %s:%s already points at %s", repository, newTag, result.Digest)
+	case result.Previous != "":
+		log.Printf("Tag moved %s:%s from %s to %s", repository, newTag, result.Previous, result.Digest)
+	default:
+		log.Printf("Tag created %s:%s", repository, newTag)
 	}
-	log.Printf("Tag created %s:%s", repository, args[1])
 }
 
 func init() {
 	rootCmd.AddCommand(tagCmd)
 	tagCmd.PersistentFlags().StringVarP(&repository, "repository", "r", "", "The ECR repository name")
+	tagCmd.Flags().BoolVar(&tagForce, "force", false, "Move the tag when it already points at another image")
+	tagCmd.Flags().StringVar(&tagDigest, "digest", "", "Digest of the image to tag instead of a source tag, e.g. sha256:...")
+	tagCmd.Flags().BoolVar(&tagDryRun, "dry-run", false, "Check the retags without writing the tags")
+	tagCmd.Flags().StringVar(&tagPlan, "plan", "", "YAML file with the retags of many repositories")
+	tagCmd.Flags().StringVar(&tagFromTag, "from-tag", "", "Tag to retag in every repository matching --repos")
+	tagCmd.Flags().StringSliceVar(&tagToTags, "to-tag", nil, "New tags of the image of --from-tag")
+	tagCmd.Flags().StringSliceVar(&tagRepos, "repos", nil, "Repository globs, e.g. 'team-*', * does not match a / and ** matches nested repositories, e.g. 'team/**'")
+	tagCmd.Flags().IntVar(&tagConcurrency, "concurrency", 5, "Number of retags to run at the same time")
+	tagCmd.MarkFlagsRequiredTogether("from-tag", "to-tag", "repos")
+	tagCmd.MarkFlagsMutuallyExclusive("plan", "from-tag")
+	tagCmd.MarkFlagsMutuallyExclusive("plan", "repository")
+	tagCmd.MarkFlagsMutuallyExclusive("from-tag", "repository")
+	tagCmd.MarkFlagsMutuallyExclusive("plan", "digest")
+	tagCmd.MarkFlagsMutuallyExclusive("from-tag", "digest")
 }
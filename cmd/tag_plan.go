@@ -0,0 +1,127 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/adpg24/devoops/aws"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// retagOutcome is the result of a retag of a plan
+type retagOutcome struct {
+	Task   aws.RetagTask
+	Status string
+	Detail string
+}
+
+// Statuses of the retags of a plan
+const (
+	retagCreated string = "created"
+	retagMoved   string = "moved"
+	retagSkipped string = "skipped"
+	retagFailed  string = "failed"
+)
+
+// runTagPlan runs the retags of --plan or of --from-tag, --to-tag and --repos and exits with 1 when one failed.
+func runTagPlan(cfg *awssdk.Config) {
+	plan := &aws.RetagPlan{Retags: []aws.RetagRule{{Repos: tagRepos, From: tagFromTag, To: tagToTags, Force: tagForce}}}
+	if tagPlan != "" {
+		var err error
+		if plan, err = aws.LoadRetagPlan(tagPlan); err != nil {
+			log.Fatalf("❌ Failed to load the plan: %v", err)
+		}
+	}
+
+	_ecr := aws.EcrService{Client: ecr.NewFromConfig(*cfg, aws.WithThrottlingRetries)}
+	repositories, err := _ecr.ListRepositories()
+	if err != nil {
+		log.Fatalf("❌ Failed to list the repositories: %v", aws.ExplainEcrError(err))
+	}
+	var names []string
+	for _, repository := range repositories {
+		names = append(names, *repository.RepositoryName)
+	}
+	tasks, err := plan.Tasks(names)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	log.Printf("ℹ Running %d retag(s) in %d repositories", len(tasks), len(names))
+
+	outcomes := make([]retagOutcome, len(tasks))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for range max(tagConcurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				outcomes[i] = retagTask(&_ecr, tasks[i])
+			}
+		}()
+	}
+	for i := range tasks {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	if !printRetagSummary(outcomes) {
+		os.Exit(1)
+	}
+}
+
+func retagTask(_ecr *aws.EcrService, task aws.RetagTask) retagOutcome {
+	outcome := retagOutcome{Task: task}
+	result, err := _ecr.Retag(task.Repository, types.ImageIdentifier{ImageTag: &task.From}, task.To, task.Force || tagForce, tagDryRun)
+	var conflict *aws.TagConflictError
+	switch {
+	case errors.As(err, &conflict):
+		outcome.Status, outcome.Detail = retagFailed, fmt.Sprintf("already points at %s, use force to move it", conflict.Digest)
+	case err != nil:
+		outcome.Status, outcome.Detail = retagFailed, err.Error()
+	case !result.Changed:
+		outcome.Status, outcome.Detail = retagSkipped, "already points at "+result.Digest
+	case result.Previous != "":
+		outcome.Status, outcome.Detail = retagMoved, fmt.Sprintf("from %s to %s", result.Previous, result.Digest)
+	default:
+		outcome.Status, outcome.Detail = retagCreated, result.Digest
+	}
+	return outcome
+}
+
+// printRetagSummary prints the outcome of every retag and the totals, false when a retag failed.
+func printRetagSummary(outcomes []retagOutcome) bool {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tREPOSITORY\tFROM\tTO\tDETAIL")
+	counts := map[string]int{}
+	for _, outcome := range outcomes {
+		counts[outcome.Status]++
+		status := outcome.Status
+		if tagDryRun && (status == retagCreated || status == retagMoved) {
+			status = "would be " + status
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", status, outcome.Task.Repository, outcome.Task.From, outcome.Task.To, outcome.Detail)
+	}
+	w.Flush()
+
+	summary := fmt.Sprintf("%d created, %d moved, %d skipped, %d failed", counts[retagCreated], counts[retagMoved], counts[retagSkipped], counts[retagFailed])
+	switch {
+	case counts[retagFailed] > 0:
+		log.Printf("❌ %s", summary)
+	case tagDryRun:
+		log.Printf("ℹ Dry run, %s", summary)
+	default:
+		log.Printf("✅ %s", summary)
+	}
+	return counts[retagFailed] == 0
+}
@@ -0,0 +1,184 @@
+/*
+Copyright © 2025 Antonio Pizarro adpg0222@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adpg24/devoops/aws"
+	"github.com/adpg24/devoops/kube"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	whereRunningRepository  string
+	whereRunningTag         string
+	whereRunningDigest      string
+	whereRunningProfiles    []string
+	whereRunningAllProfiles bool
+	whereRunningRegions     []string
+	whereRunningTimeout     time.Duration
+)
+
+// imageLocation is a workload of a kube context or ECS cluster that runs the image
+type imageLocation struct {
+	Where     string
+	Namespace string
+	Workload  string
+	Replicas  int
+}
+
+// whereRunningCmd represents the where-running command
+var whereRunningCmd = &cobra.Command{
+	Use:   "where-running",
+	Short: "Find the kube contexts and ECS services that run an ECR image",
+	Long: `Find the kube contexts and ECS services that run an ECR image, e.g. when a vulnerability lands.
+
+The tag is resolved to the digest of the image. Every kube context is searched for pods running the
+digest and for deployments, stateful sets and daemon sets that reference the tag or digest. Every ECS
+cluster of the selected profiles and regions is searched for services whose tasks run the digest or
+whose task definition references the tag or digest. All contexts and profiles are searched in parallel.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := aws.GetAwsConfig(&aws.AwsConfig{})
+		if err != nil {
+			log.Fatalf("❌ Failed to load AWS config: %v", err)
+		}
+		_ecr := aws.EcrService{Client: ecr.NewFromConfig(*cfg)}
+		uri, err := _ecr.GetRepositoryUri(whereRunningRepository)
+		if err != nil {
+			log.Fatalf("❌ Failed to describe repository %s: %v", whereRunningRepository, err)
+		}
+		digest := whereRunningDigest
+		if digest == "" {
+			image, err := _ecr.GetImage(whereRunningRepository, types.ImageIdentifier{ImageTag: &whereRunningTag})
+			if err != nil {
+				log.Fatalf("❌ Image %s:%s does not exist: %v", whereRunningRepository, whereRunningTag, err)
+			}
+			digest = *image.ImageId.ImageDigest
+		}
+		log.Printf("ℹ Looking for %s@%s", whereRunningRepository, digest)
+
+		var (
+			mu        sync.Mutex
+			wg        sync.WaitGroup
+			locations []imageLocation
+		)
+		found := func(location imageLocation) {
+			mu.Lock()
+			defer mu.Unlock()
+			locations = append(locations, location)
+		}
+
+		kubeConfig := kube.NewKubeConfig("")
+		for _, kubeContext := range kubeConfig.GetContexts() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				findImageInContext(kubeConfig, kubeContext, uri, digest, found)
+			}()
+		}
+		for _, profile := range selectAwsProfiles(whereRunningProfiles, whereRunningAllProfiles) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				findImageInEcs(profile, uri, digest, found)
+			}()
+		}
+		wg.Wait()
+
+		if len(locations) == 0 {
+			log.Println("ℹ The image is not running anywhere")
+			return
+		}
+		sort.Slice(locations, func(i, j int) bool {
+			a, b := locations[i], locations[j]
+			return a.Where+"/"+a.Namespace+"/"+a.Workload < b.Where+"/"+b.Namespace+"/"+b.Workload
+		})
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CONTEXT/CLUSTER\tNAMESPACE\tWORKLOAD\tREPLICAS")
+		for _, location := range locations {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", location.Where, location.Namespace, location.Workload, location.Replicas)
+		}
+		w.Flush()
+	},
+}
+
+func findImageInContext(kubeConfig *kube.KubeConfig, kubeContext string, uri string, digest string, found func(imageLocation)) {
+	client, err := kube.GetClientForContext(kubeConfig, kubeContext)
+	if err != nil {
+		log.Printf("⚠ Skipping context %s: %v", kubeContext, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), whereRunningTimeout)
+	defer cancel()
+
+	usages, err := kube.FindImage(ctx, client, uri, whereRunningTag, digest)
+	if err != nil {
+		log.Printf("⚠ Failed to search context %s: %v", kubeContext, err)
+		return
+	}
+	for _, usage := range usages {
+		found(imageLocation{Where: kubeContext, Namespace: usage.Namespace, Workload: strings.ToLower(usage.Kind) + "/" + usage.Name, Replicas: usage.Replicas})
+	}
+}
+
+func findImageInEcs(profile string, uri string, digest string, found func(imageLocation)) {
+	profileConfig, err := aws.GetAwsConfig(&aws.AwsConfig{Profile: profile})
+	if err != nil {
+		log.Printf("⚠ Skipping profile %s, failed to load AWS config: %v", profile, err)
+		return
+	}
+	regions := whereRunningRegions
+	if len(regions) == 0 {
+		regions = []string{region}
+		if profileConfig.Region != "" {
+			regions = []string{profileConfig.Region}
+		}
+	}
+
+	for _, r := range regions {
+		cfg, err := aws.GetAwsConfig(&aws.AwsConfig{Profile: profile, Region: r})
+		if err != nil {
+			log.Printf("⚠ Skipping profile %s in %s, failed to load AWS config: %v", profile, r, err)
+			continue
+		}
+		replicas := map[string]int{}
+		findRepositoryInEcs(cfg, uri, func(reference string, cluster string, service string, n int) {
+			if reference == digest || (whereRunningTag != "" && reference == whereRunningTag) {
+				replicas[cluster+"/"+service] += n
+			}
+		}, func(source string, format string, args ...any) {
+			log.Printf("⚠ %s in %s: "+format, append([]any{profile, r}, args...)...)
+		})
+		for key, n := range replicas {
+			cluster, service, _ := strings.Cut(key, "/")
+			found(imageLocation{Where: fmt.Sprintf("%s/%s/%s", profile, r, cluster), Namespace: "-", Workload: "service/" + service, Replicas: n})
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(whereRunningCmd)
+	whereRunningCmd.Flags().StringVarP(&whereRunningRepository, "repository", "r", "", "The ECR repository name")
+	whereRunningCmd.Flags().StringVarP(&whereRunningTag, "tag", "t", "", "The image tag, resolved to its digest")
+	whereRunningCmd.Flags().StringVar(&whereRunningDigest, "digest", "", "The image digest, e.g. sha256:...")
+	whereRunningCmd.Flags().StringSliceVarP(&whereRunningProfiles, "profile", "p", []string{}, "AWS profiles to search for ECS services (default $AWS_PROFILE)")
+	whereRunningCmd.Flags().BoolVar(&whereRunningAllProfiles, "all-profiles", false, "Search all profiles defined in ~/.aws/credentials")
+	whereRunningCmd.Flags().StringSliceVar(&whereRunningRegions, "region", []string{}, "AWS regions to search for ECS services (default the region of the profile)")
+	whereRunningCmd.Flags().DurationVar(&whereRunningTimeout, "timeout", 30*time.Second, "Timeout to search a kube context")
+	whereRunningCmd.MarkFlagRequired("repository")
+	whereRunningCmd.MarkFlagsOneRequired("tag", "digest")
+	whereRunningCmd.MarkFlagsMutuallyExclusive("tag", "digest")
+}
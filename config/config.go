@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigEnv overrides the location of the devoops config file (default ~/.devoops.yaml)
+const ConfigEnv string = "DEVOOPS_CONFIG"
+
+type Config struct {
+	Environments []Environment `yaml:"environments"`
+	Protected    []Protection  `yaml:"protected"`
+	// Forwards are named sets of port-forwards, see devoops forward
+	Forwards map[string][]Forward `yaml:"forwards"`
+}
+
+// Environment assigns a color to the kube contexts and AWS profiles whose name matches the pattern.
+type Environment struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+	Color   string `yaml:"color"`
+}
+
+// Protection protects the kube contexts and AWS profiles whose name matches the pattern
+// or that belong to the AWS account, see the guard package.
+type Protection struct {
+	Pattern string `yaml:"pattern"`
+	Account string `yaml:"account"`
+}
+
+// Forward forwards a local port to a port of a service or pod. Pod is the name of a pod or
+// a label selector, context and namespace default to those of the current context.
+type Forward struct {
+	Context   string `yaml:"context"`
+	Namespace string `yaml:"namespace"`
+	Service   string `yaml:"service"`
+	Pod       string `yaml:"pod"`
+	Local     int    `yaml:"local"`
+	Remote    int    `yaml:"remote"`
+}
+
+func ConfigPath() string {
+	if configPath := os.Getenv(ConfigEnv); configPath != "" {
+		return configPath
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return filepath.Join(home, ".devoops.yaml")
+}
+
+// Load reads the devoops config file, a missing file results in an empty config.
+func Load() (*Config, error) {
+	config := &Config{}
+
+	content, err := os.ReadFile(ConfigPath())
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the devoops config %s: %w", ConfigPath(), err)
+	}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("failed to parse the devoops config %s: %w", ConfigPath(), err)
+	}
+	return config, nil
+}
+
+// GetEnvironment returns the first environment whose pattern matches the name.
+func (c *Config) GetEnvironment(name string) *Environment {
+	for i, environment := range c.Environments {
+		if matched, _ := path.Match(environment.Pattern, name); matched {
+			return &c.Environments[i]
+		}
+	}
+	return nil
+}
+
+// IsProtected reports whether a protection matches the name or the account.
+func (c *Config) IsProtected(name string, account string) bool {
+	for _, protection := range c.Protected {
+		if protection.Pattern != "" {
+			if matched, _ := path.Match(protection.Pattern, name); matched {
+				return true
+			}
+		}
+		if protection.Account != "" && protection.Account == account {
+			return true
+		}
+	}
+	return false
+}
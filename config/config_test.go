@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "devoops.yaml")
+	t.Setenv(ConfigEnv, configPath)
+
+	if config, err := Load(); err != nil || len(config.Environments) != 0 {
+		t.Fatalf("Expected an empty config when the file does not exist, got %v", err)
+	}
+
+	content := `
+environments:
+  - name: production
+    pattern: "*prod*"
+    color: red
+  - name: other
+    pattern: "*"
+    color: green
+forwards:
+  db:
+    - service: postgres
+      namespace: data
+      local: 15432
+      remote: 5432`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load the config: %v", err)
+	}
+	if environment := config.GetEnvironment("eks-prod-1"); environment == nil || environment.Color != "red" {
+		t.Fatalf("Expected the production environment, got %v", environment)
+	}
+	if environment := config.GetEnvironment("dev"); environment == nil || environment.Name != "other" {
+		t.Fatalf("Expected the first matching environment, got %v", environment)
+	}
+	if forwards := config.Forwards["db"]; len(forwards) != 1 || forwards[0].Service != "postgres" || forwards[0].Local != 15432 {
+		t.Fatalf("Expected the db forward, got %v", forwards)
+	}
+
+	if err := os.WriteFile(configPath, []byte("environments: {"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := Load(); err == nil {
+		t.Fatalf("Expected an error for an invalid config")
+	}
+}
+
+func TestIsProtected(t *testing.T) {
+	config := &Config{Protected: []Protection{{Pattern: "*prod*"}, {Account: "123456789012"}}}
+
+	if !config.IsProtected("eks-prod", "") {
+		t.Fatalf("Expected eks-prod to be protected by its name")
+	}
+	if !config.IsProtected("dev", "123456789012") {
+		t.Fatalf("Expected dev to be protected by its account")
+	}
+	if config.IsProtected("dev", "210987654321") {
+		t.Fatalf("Expected dev not to be protected")
+	}
+}
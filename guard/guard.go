@@ -0,0 +1,81 @@
+// Package guard protects production kube contexts and AWS profiles. The kube and AWS
+// commands share these checks so the protection rules can not drift apart.
+package guard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/adpg24/devoops/config"
+)
+
+// ConfirmFlag must be given to run a mutating command against a protected target
+const ConfirmFlag string = "yes-i-mean-prod"
+
+const (
+	KubeContext string = "kube context"
+	AwsProfile  string = "AWS profile"
+)
+
+type Target struct {
+	// Kind is KubeContext or AwsProfile
+	Kind    string
+	Name    string
+	Account string
+}
+
+func (t Target) String() string {
+	if t.Account != "" {
+		return fmt.Sprintf("%s %s (account %s)", t.Kind, t.Name, t.Account)
+	}
+	return fmt.Sprintf("%s %s", t.Kind, t.Name)
+}
+
+// IsProtected reports whether the devoops config protects the target. A config that cannot be
+// read is an error rather than an unprotected target.
+func IsProtected(target Target) (bool, error) {
+	devoopsConfig, err := config.Load()
+	if err != nil {
+		return false, err
+	}
+	return devoopsConfig.IsProtected(target.Name, target.Account), nil
+}
+
+// Banner returns a red banner that warns the target is protected.
+func Banner(target Target) string {
+	line := strings.Repeat("!", len(target.String())+8)
+	return fmt.Sprintf("\033[1;41;97m%s\n!!! %s !!!\n%s\033[0m", line, strings.ToUpper(target.String()), line)
+}
+
+// ConfirmSwitch asks to type the name of a protected target before switching to it.
+// It returns nil when the target is not protected.
+func ConfirmSwitch(target Target) error {
+	protected, err := IsProtected(target)
+	if err != nil || !protected {
+		return err
+	}
+	fmt.Println(Banner(target))
+
+	var answer string
+	prompt := &survey.Input{Message: fmt.Sprintf("%s is protected, type its name to confirm:", target.Name)}
+	if err := survey.AskOne(prompt, &answer); err != nil {
+		return err
+	}
+	if answer != target.Name {
+		return fmt.Errorf("the name does not match %s", target.Name)
+	}
+	return nil
+}
+
+// Check refuses mutations of protected targets unless confirmed with --yes-i-mean-prod.
+func Check(target Target, confirmed bool) error {
+	if confirmed {
+		return nil
+	}
+	protected, err := IsProtected(target)
+	if err != nil || !protected {
+		return err
+	}
+	return fmt.Errorf("%s is protected, add --%s to run this command against it", target, ConfirmFlag)
+}
@@ -0,0 +1,116 @@
+package kube
+
+import (
+	"context"
+	"sync"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// AccessResource is a resource of the access matrix
+type AccessResource struct {
+	Group       string
+	Resource    string
+	Subresource string
+	// Namespaced resources are checked in the namespace, others cluster-wide
+	Namespaced bool
+}
+
+func (r AccessResource) String() string {
+	name := r.Resource
+	if r.Group != "" {
+		name += "." + r.Group
+	}
+	if r.Subresource != "" {
+		name += "/" + r.Subresource
+	}
+	return name
+}
+
+// AccessVerbs are the verbs of the access matrix
+var AccessVerbs = []string{"get", "list", "create", "patch", "delete"}
+
+// AccessResources are the resources of the access matrix, the ones asked about most
+var AccessResources = []AccessResource{
+	{Resource: "pods", Namespaced: true},
+	{Resource: "pods", Subresource: "log", Namespaced: true},
+	{Resource: "pods", Subresource: "exec", Namespaced: true},
+	{Resource: "services", Namespaced: true},
+	{Resource: "configmaps", Namespaced: true},
+	{Resource: "secrets", Namespaced: true},
+	{Group: "apps", Resource: "deployments", Namespaced: true},
+	{Group: "apps", Resource: "statefulsets", Namespaced: true},
+	{Group: "batch", Resource: "jobs", Namespaced: true},
+	{Group: "batch", Resource: "cronjobs", Namespaced: true},
+	{Group: "networking.k8s.io", Resource: "ingresses", Namespaced: true},
+	{Resource: "namespaces"},
+	{Resource: "nodes"},
+}
+
+// Access is a row of the access matrix
+type Access struct {
+	Resource AccessResource
+	// Allowed by verb
+	Allowed map[string]bool
+}
+
+// CanI asks the API server with SelfSubjectAccessReviews which verbs the current identity
+// is allowed on the resources in the namespace.
+func CanI(ctx context.Context, client kubernetes.Interface, namespace string, resources []AccessResource, verbs []string) ([]Access, error) {
+	matrix := make([]Access, len(resources))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for i, resource := range resources {
+		matrix[i] = Access{Resource: resource, Allowed: map[string]bool{}}
+		for _, verb := range verbs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				attributes := &authorizationv1.ResourceAttributes{Verb: verb, Group: resource.Group, Resource: resource.Resource, Subresource: resource.Subresource}
+				if resource.Namespaced {
+					attributes.Namespace = namespace
+				}
+				review := &authorizationv1.SelfSubjectAccessReview{Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attributes}}
+				result, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+					return
+				}
+				matrix[i].Allowed[verb] = result.Status.Allowed
+			}()
+		}
+	}
+	wg.Wait()
+	return matrix, firstErr
+}
+
+// ListRules returns the rules of the current identity in the namespace. The rules may be
+// incomplete, e.g. on EKS where authorization is partly done by a webhook.
+func ListRules(ctx context.Context, client kubernetes.Interface, namespace string) (*authorizationv1.SubjectRulesReviewStatus, error) {
+	review := &authorizationv1.SelfSubjectRulesReview{Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace}}
+	result, err := client.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &result.Status, nil
+}
+
+// WhoAmI returns the user the API server authenticates the credentials as.
+func WhoAmI(ctx context.Context, client kubernetes.Interface) (*authenticationv1.UserInfo, error) {
+	result, err := client.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &result.Status.UserInfo, nil
+}
@@ -0,0 +1,156 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// the aws-auth config map maps IAM roles and users to Kubernetes users and groups on EKS
+const (
+	AwsAuthNamespace string = "kube-system"
+	AwsAuthName      string = "aws-auth"
+)
+
+type AwsAuthMapping struct {
+	RoleArn  string   `yaml:"rolearn,omitempty"`
+	UserArn  string   `yaml:"userarn,omitempty"`
+	Username string   `yaml:"username"`
+	Groups   []string `yaml:"groups,omitempty"`
+}
+
+// Arn returns the ARN of the role or user.
+func (m *AwsAuthMapping) Arn() string {
+	if m.RoleArn != "" {
+		return m.RoleArn
+	}
+	return m.UserArn
+}
+
+type AwsAuth struct {
+	ConfigMap *corev1.ConfigMap
+	Roles     []AwsAuthMapping
+	Users     []AwsAuthMapping
+}
+
+// GetAwsAuth reads the aws-auth config map, nil when the cluster does not have one.
+func GetAwsAuth(ctx context.Context, client kubernetes.Interface) (*AwsAuth, error) {
+	configMap, err := client.CoreV1().ConfigMaps(AwsAuthNamespace).Get(ctx, AwsAuthName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	awsAuth := &AwsAuth{ConfigMap: configMap}
+	if err := yaml.Unmarshal([]byte(configMap.Data["mapRoles"]), &awsAuth.Roles); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal([]byte(configMap.Data["mapUsers"]), &awsAuth.Users); err != nil {
+		return nil, err
+	}
+	return awsAuth, nil
+}
+
+// Find returns the mapping of the role or user ARN, nil when it is not mapped.
+func (a *AwsAuth) Find(arn string) *AwsAuthMapping {
+	for i := range a.Roles {
+		if a.Roles[i].RoleArn == arn {
+			return &a.Roles[i]
+		}
+	}
+	for i := range a.Users {
+		if a.Users[i].UserArn == arn {
+			return &a.Users[i]
+		}
+	}
+	return nil
+}
+
+// Duplicates returns the role and user ARNs that are mapped more than once.
+func (a *AwsAuth) Duplicates() []string {
+	var duplicates []string
+	seen := map[string]int{}
+	for _, mappings := range [][]AwsAuthMapping{a.Roles, a.Users} {
+		for _, mapping := range mappings {
+			seen[mapping.Arn()]++
+			if seen[mapping.Arn()] == 2 {
+				duplicates = append(duplicates, mapping.Arn())
+			}
+		}
+	}
+	return duplicates
+}
+
+// Set adds the mapping, replacing the mapping of the same role or user.
+func (a *AwsAuth) Set(mapping AwsAuthMapping) {
+	mappings := &a.Users
+	if mapping.RoleArn != "" {
+		mappings = &a.Roles
+	}
+	for i := range *mappings {
+		if (*mappings)[i].Arn() == mapping.Arn() {
+			(*mappings)[i] = mapping
+			return
+		}
+	}
+	*mappings = append(*mappings, mapping)
+}
+
+// Remove removes the mapping of the role or user, false when it is not mapped.
+func (a *AwsAuth) Remove(arn string) bool {
+	before := len(a.Roles) + len(a.Users)
+	a.Roles = slices.DeleteFunc(a.Roles, func(m AwsAuthMapping) bool { return m.Arn() == arn })
+	a.Users = slices.DeleteFunc(a.Users, func(m AwsAuthMapping) bool { return m.Arn() == arn })
+	return len(a.Roles)+len(a.Users) < before
+}
+
+// Data returns the data of the config map with the mappings, keeping its other keys.
+func (a *AwsAuth) Data() (map[string]string, error) {
+	data := map[string]string{}
+	if a.ConfigMap != nil {
+		for key, value := range a.ConfigMap.Data {
+			data[key] = value
+		}
+	}
+	for key, mappings := range map[string][]AwsAuthMapping{"mapRoles": a.Roles, "mapUsers": a.Users} {
+		if len(mappings) == 0 {
+			delete(data, key)
+			continue
+		}
+		var buffer bytes.Buffer
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(mappings); err != nil {
+			return nil, err
+		}
+		data[key] = buffer.String()
+	}
+	return data, nil
+}
+
+// UpdateAwsAuth writes the mappings to the aws-auth config map, which is created when it does not exist.
+func UpdateAwsAuth(ctx context.Context, client kubernetes.Interface, awsAuth *AwsAuth) error {
+	data, err := awsAuth.Data()
+	if err != nil {
+		return err
+	}
+
+	configMaps := client.CoreV1().ConfigMaps(AwsAuthNamespace)
+	if awsAuth.ConfigMap == nil {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: AwsAuthName, Namespace: AwsAuthNamespace}, Data: data}
+		awsAuth.ConfigMap, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+		return err
+	}
+	configMap := awsAuth.ConfigMap.DeepCopy()
+	configMap.Data = data
+	awsAuth.ConfigMap, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
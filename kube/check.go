@@ -0,0 +1,221 @@
+package kube
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adpg24/devoops/aws"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+type ContextHealth struct {
+	Context string
+	// Error is set when the client could not be built or the API server did not respond
+	Error error
+	// ServerVersion is empty when the server rejected the credentials before telling its version
+	ServerVersion string
+	// VersionSkew is the number of minor versions the server is ahead (+) or behind (-) the client
+	VersionSkew int
+	AuthError   error
+	// CredentialExpiry is the expiry of the client certificate or token, nil when unknown
+	CredentialExpiry *time.Time
+	// ExecCredential is set when the credentials come from an exec plugin, for devoops eks token
+	// the CredentialExpiry is the expiry of the cached token
+	ExecCredential bool
+	CaExpiry       *time.Time
+}
+
+func (h *ContextHealth) Reachable() bool {
+	return h.Error == nil
+}
+
+func (h *ContextHealth) Authenticated() bool {
+	return h.Error == nil && h.AuthError == nil
+}
+
+// CheckContext checks if the API server of the context is reachable within the timeout
+// and accepts the credentials of the context.
+func (kc *KubeConfig) CheckContext(contextName string, timeout time.Duration) *ContextHealth {
+	health := &ContextHealth{Context: contextName}
+
+	config, err := kc.ClientConfig(contextName).ClientConfig()
+	if err != nil {
+		health.Error = err
+		return health
+	}
+	config.Timeout = timeout
+	health.CredentialExpiry = credentialExpiry(config)
+	health.ExecCredential = config.ExecProvider != nil
+	health.CaExpiry = caExpiry(config)
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		health.Error = err
+		return health
+	}
+
+	version, err := client.Discovery().ServerVersion()
+	// a server that rejects the credentials answered, it is reachable
+	if apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err) {
+		health.AuthError = err
+		return health
+	}
+	if err != nil {
+		health.Error = err
+		return health
+	}
+	health.ServerVersion = version.GitVersion
+	if clientMinor, ok := clientMinorVersion(); ok {
+		health.VersionSkew = minorVersion(version.Minor) - clientMinor
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	namespace, _, _ := kc.ClientConfig(contextName).Namespace()
+	review := &authorizationv1.SelfSubjectRulesReview{Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace}}
+	_, health.AuthError = client.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, review, metav1.CreateOptions{})
+
+	return health
+}
+
+// clientMinorVersion returns the minor Kubernetes version of the client-go library
+// devoops is built with, client-go v0.30.x talks to Kubernetes 1.30.
+func clientMinorVersion() (int, bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return 0, false
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "k8s.io/client-go" {
+			parts := strings.Split(dep.Version, ".")
+			if len(parts) < 2 {
+				return 0, false
+			}
+			minor, err := strconv.Atoi(parts[1])
+			return minor, err == nil
+		}
+	}
+	return 0, false
+}
+
+// minorVersion parses minor versions like "29" or "29+" as reported by EKS.
+func minorVersion(minor string) int {
+	version, _ := strconv.Atoi(strings.TrimRight(minor, "+"))
+	return version
+}
+
+func credentialExpiry(config *rest.Config) *time.Time {
+	if config.ExecProvider != nil {
+		return execCredentialExpiry(config.ExecProvider)
+	}
+	if certs := readCertificates(config.CertData, config.CertFile); len(certs) > 0 {
+		return &certs[0].NotAfter
+	}
+
+	token := config.BearerToken
+	if token == "" && config.BearerTokenFile != "" {
+		if content, err := os.ReadFile(config.BearerTokenFile); err == nil {
+			token = strings.TrimSpace(string(content))
+		}
+	}
+	return tokenExpiry(token)
+}
+
+// execCredentialExpiry returns the expiry of the token that devoops eks token cached for the exec
+// plugin, nil for other plugins or when no token is cached.
+func execCredentialExpiry(exec *clientcmdapi.ExecConfig) *time.Time {
+	if !strings.Contains(filepath.Base(exec.Command), "devoops") || len(exec.Args) < 2 || exec.Args[0] != "eks" || exec.Args[1] != "token" {
+		return nil
+	}
+	flag := func(name string) string {
+		if i := slices.Index(exec.Args, name); i >= 0 && i+1 < len(exec.Args) {
+			return exec.Args[i+1]
+		}
+		return ""
+	}
+	profile := flag("--profile")
+	if profile == "" {
+		return nil
+	}
+	path, err := aws.EksTokenCachePath(profile, flag("--region"), flag("--cluster-name"))
+	if err != nil {
+		return nil
+	}
+	if token := aws.ReadCachedEksToken(path); token != nil {
+		return &token.Expiration
+	}
+	return nil
+}
+
+func caExpiry(config *rest.Config) *time.Time {
+	var expiry *time.Time
+	for _, cert := range readCertificates(config.CAData, config.CAFile) {
+		if expiry == nil || cert.NotAfter.Before(*expiry) {
+			expiry = &cert.NotAfter
+		}
+	}
+	return expiry
+}
+
+func readCertificates(data []byte, file string) []*x509.Certificate {
+	if len(data) == 0 && file != "" {
+		data, _ = os.ReadFile(file)
+	}
+
+	var certs []*x509.Certificate
+	for block, remaining := pem.Decode(data); block != nil; block, remaining = pem.Decode(remaining) {
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+	return certs
+}
+
+// tokenExpiry returns the exp claim of a JWT, e.g. a service account token.
+func tokenExpiry(token string) *time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return nil
+	}
+	expiry := time.Unix(claims.Exp, 0)
+	return &expiry
+}
+
+// FormatExpiry formats the expiry relative to now, e.g. "in 30d" or "expired 2d ago".
+func FormatExpiry(expiry *time.Time) string {
+	if expiry == nil {
+		return "-"
+	}
+	if remaining := time.Until(*expiry); remaining > 0 {
+		return fmt.Sprintf("in %s", duration.HumanDuration(remaining))
+	}
+	return fmt.Sprintf("expired %s ago", duration.HumanDuration(-time.Until(*expiry)))
+}
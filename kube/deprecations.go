@@ -0,0 +1,179 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/metadata"
+)
+
+const lastAppliedAnnotation string = "kubectl.kubernetes.io/last-applied-configuration"
+
+// RemovedApi is an API version of a kind that is no longer served from a Kubernetes minor
+type RemovedApi struct {
+	GroupVersion string
+	Kind         string
+	RemovedIn    string
+	// Replacement is the group version to migrate to, "" when the kind was removed without one
+	Replacement string
+}
+
+// RemovedApis is the deprecation table, see https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+var RemovedApis = []RemovedApi{
+	{"extensions/v1beta1", "Deployment", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "DaemonSet", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "ReplicaSet", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "NetworkPolicy", "1.16", "networking.k8s.io/v1"},
+	{"apps/v1beta1", "Deployment", "1.16", "apps/v1"},
+	{"apps/v1beta1", "StatefulSet", "1.16", "apps/v1"},
+	{"apps/v1beta2", "Deployment", "1.16", "apps/v1"},
+	{"apps/v1beta2", "StatefulSet", "1.16", "apps/v1"},
+	{"apps/v1beta2", "DaemonSet", "1.16", "apps/v1"},
+	{"apps/v1beta2", "ReplicaSet", "1.16", "apps/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", "1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", "1.22", "admissionregistration.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "1.22", "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", "1.22", "apiregistration.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", "1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", "1.22", "coordination.k8s.io/v1"},
+	{"extensions/v1beta1", "Ingress", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", "1.22", "networking.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", "1.22", "storage.k8s.io/v1"},
+	{"batch/v1beta1", "CronJob", "1.25", "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "1.25", "autoscaling/v2"},
+	{"policy/v1beta1", "PodDisruptionBudget", "1.25", "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", "1.25", ""},
+	{"node.k8s.io/v1beta1", "RuntimeClass", "1.25", "node.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "FlowSchema", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "PriorityLevelConfiguration", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "1.26", "autoscaling/v2"},
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", "1.27", "storage.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "FlowSchema", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "PriorityLevelConfiguration", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "FlowSchema", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "PriorityLevelConfiguration", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// ApisRemovedIn returns the APIs of the deprecation table that are removed in the minor, e.g. 1.25.
+func ApisRemovedIn(minor string) []RemovedApi {
+	var removed []RemovedApi
+	for _, api := range RemovedApis {
+		if api.RemovedIn == minor {
+			removed = append(removed, api)
+		}
+	}
+	return removed
+}
+
+// NextMinor returns the minor after the version, e.g. 1.30 for 1.29.
+func NextMinor(version string) (string, error) {
+	major, minor, ok := strings.Cut(strings.TrimPrefix(version, "v"), ".")
+	n, err := strconv.Atoi(strings.SplitN(minor, ".", 2)[0])
+	if !ok || err != nil {
+		return "", fmt.Errorf("invalid Kubernetes version %q", version)
+	}
+	return fmt.Sprintf("%s.%d", major, n+1), nil
+}
+
+// RemovedApiUsage is an object last applied with a removed API
+type RemovedApiUsage struct {
+	Api       RemovedApi
+	Namespace string
+	Name      string
+}
+
+// FindRemovedApis returns the removed APIs the cluster still serves and the objects whose
+// last applied manifest uses one of them. Objects are listed with any served version of their
+// kind, the API server converts them, so only the last applied manifest tells the version used.
+func FindRemovedApis(ctx context.Context, discoveryClient discovery.DiscoveryInterface, metadataClient metadata.Interface, removed []RemovedApi) ([]RemovedApi, []RemovedApiUsage, error) {
+	// partial results are returned when some groups fail, e.g. an unavailable metrics server
+	_, resourceLists, err := discoveryClient.ServerGroupsAndResources()
+	if err != nil && len(resourceLists) == 0 {
+		return nil, nil, err
+	}
+
+	// resources by group and kind, and the kinds served by group version
+	resources := map[schema.GroupKind]schema.GroupVersionResource{}
+	served := map[string]bool{}
+	for _, list := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			served[list.GroupVersion+"/"+resource.Kind] = true
+			groupKind := schema.GroupKind{Group: groupVersion.Group, Kind: resource.Kind}
+			if _, ok := resources[groupKind]; !ok {
+				resources[groupKind] = groupVersion.WithResource(resource.Name)
+			}
+		}
+	}
+
+	var servedApis []RemovedApi
+	var usages []RemovedApiUsage
+	listed := map[schema.GroupVersionResource][]metav1.PartialObjectMetadata{}
+	for _, api := range removed {
+		if served[api.GroupVersion+"/"+api.Kind] {
+			servedApis = append(servedApis, api)
+		}
+
+		// the kind may have moved to the group of its replacement, e.g. Ingress from extensions
+		var groups []string
+		for _, groupVersion := range []string{api.GroupVersion, api.Replacement} {
+			if gv, err := schema.ParseGroupVersion(groupVersion); err == nil && groupVersion != "" {
+				groups = append(groups, gv.Group)
+			}
+		}
+		for _, group := range groups {
+			resource, ok := resources[schema.GroupKind{Group: group, Kind: api.Kind}]
+			if !ok {
+				continue
+			}
+			objects, ok := listed[resource]
+			if !ok {
+				list, err := metadataClient.Resource(resource).List(ctx, metav1.ListOptions{})
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to list %s: %w", resource.String(), err)
+				}
+				objects = list.Items
+				listed[resource] = objects
+			}
+			for _, object := range objects {
+				if lastAppliedApi(object) == api.GroupVersion+"/"+api.Kind {
+					usages = append(usages, RemovedApiUsage{Api: api, Namespace: object.Namespace, Name: object.Name})
+				}
+			}
+			break
+		}
+	}
+	return servedApis, usages, nil
+}
+
+// lastAppliedApi returns the apiVersion/kind of the last applied manifest, "" when there is none.
+func lastAppliedApi(object metav1.PartialObjectMetadata) string {
+	var manifest metav1.TypeMeta
+	if err := json.Unmarshal([]byte(object.Annotations[lastAppliedAnnotation]), &manifest); err != nil {
+		return ""
+	}
+	return manifest.APIVersion + "/" + manifest.Kind
+}
@@ -0,0 +1,142 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Diff lists the cluster, user and context entries that were added (+), changed (~)
+// or removed (-) between two versions of a kube config. A changed entry is followed by
+// the lines of its fields that were removed or added, with secrets redacted.
+func Diff(before *api.Config, after *api.Config) []string {
+	var lines []string
+	lines = append(lines, diffEntries("cluster", before.Clusters, after.Clusters)...)
+	lines = append(lines, diffEntries("user", before.AuthInfos, after.AuthInfos)...)
+	lines = append(lines, diffEntries("context", before.Contexts, after.Contexts)...)
+	if before.CurrentContext != after.CurrentContext {
+		lines = append(lines, fmt.Sprintf("~ current-context %q -> %q", before.CurrentContext, after.CurrentContext))
+	}
+	return lines
+}
+
+func diffEntries[T any](kind string, before map[string]T, after map[string]T) []string {
+	var names []string
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var lines []string
+	for _, name := range names {
+		previous, inBefore := before[name]
+		current, inAfter := after[name]
+		switch {
+		case !inBefore:
+			lines = append(lines, fmt.Sprintf("+ %s %s", kind, name))
+		case !inAfter:
+			lines = append(lines, fmt.Sprintf("- %s %s", kind, name))
+		case !reflect.DeepEqual(previous, current):
+			line := fmt.Sprintf("~ %s %s", kind, name)
+			for _, field := range DiffLines(renderEntry(previous), renderEntry(current)) {
+				if !strings.HasPrefix(field, "  ") {
+					line += "\n    " + field
+				}
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// renderEntry renders a cluster, user or context entry as YAML like kubectl config view does,
+// certificate data is omitted and tokens and passwords are redacted.
+func renderEntry(entry any) string {
+	config := api.NewConfig()
+	var redacted any
+	switch entry := entry.(type) {
+	case *api.Cluster:
+		config.Clusters[""] = entry.DeepCopy()
+		redacted = config.Clusters[""]
+	case *api.AuthInfo:
+		config.AuthInfos[""] = entry.DeepCopy()
+		redacted = config.AuthInfos[""]
+	case *api.Context:
+		config.Contexts[""] = entry.DeepCopy()
+		redacted = config.Contexts[""]
+	}
+	api.ShortenConfig(config)
+	if err := api.RedactSecrets(config); err != nil {
+		return ""
+	}
+
+	content, err := json.Marshal(redacted)
+	if err != nil {
+		return ""
+	}
+	var fields any
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return ""
+	}
+	content, err = yaml.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// DiffLines returns the lines of before and after prefixed with "  " when unchanged,
+// "- " when removed and "+ " when added.
+func DiffLines(before string, after string) []string {
+	a, b := splitLines(before), splitLines(after)
+
+	// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i, j = i+1, j+1
+		// like diff, the removed lines of a changed hunk come before the added ones
+		case i < len(a) && (j == len(b) || common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	return lines
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
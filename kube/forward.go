@@ -0,0 +1,216 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// how often a forwarded pod is checked, a pod that is gone is replaced by another one
+const forwardPodCheckInterval = 2 * time.Second
+
+type ForwardTarget struct {
+	Namespace string
+	// Service forwards to a ready pod of the service, RemotePort is a port of the service
+	Service string
+	// Pod is the name of a pod or a label selector, e.g. app=api
+	Pod        string
+	LocalPort  int
+	RemotePort int
+}
+
+func (t ForwardTarget) String() string {
+	if t.Service != "" {
+		return fmt.Sprintf("localhost:%d -> service/%s:%d", t.LocalPort, t.Service, t.RemotePort)
+	}
+	return fmt.Sprintf("localhost:%d -> pod/%s:%d", t.LocalPort, t.Pod, t.RemotePort)
+}
+
+// forwardSetupError is an error that reconnecting does not fix, e.g. a local port in use
+type forwardSetupError struct {
+	error
+}
+
+func (e forwardSetupError) Unwrap() error {
+	return e.error
+}
+
+// Forward forwards the local port to the target until ctx is done. When the pod goes
+// away or the connection is lost, it reconnects to the target, e.g. a new pod of the service.
+// It returns an error when the forward cannot be set up, e.g. the local port is in use.
+func Forward(ctx context.Context, config *rest.Config, client kubernetes.Interface, target ForwardTarget, out io.Writer) error {
+	for ctx.Err() == nil {
+		pod, port, err := resolveForwardTarget(ctx, client, target)
+		if err == nil {
+			fmt.Fprintf(out, "%s: forwarding to pod %s\n", target, pod)
+			err = forwardPod(ctx, config, client, target.Namespace, pod, target.LocalPort, port, out)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		var setupErr forwardSetupError
+		if errors.As(err, &setupErr) {
+			return setupErr.error
+		}
+		if err != nil {
+			fmt.Fprintf(out, "%s: %v, reconnecting\n", target, err)
+		} else {
+			fmt.Fprintf(out, "%s: pod %s is gone, reconnecting\n", target, pod)
+		}
+
+		select {
+		case <-time.After(forwardPodCheckInterval):
+		case <-ctx.Done():
+		}
+	}
+	return nil
+}
+
+// forwardPod forwards the port until ctx is done, the connection is lost or the pod is gone.
+func forwardPod(ctx context.Context, config *rest.Config, client kubernetes.Interface, namespace string, pod string, localPort int, remotePort int, out io.Writer) error {
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return forwardSetupError{err}
+	}
+	// the port forwarder only listens after connecting to the pod, and reports a port in use
+	// like any other failure
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", localPort))
+	if err != nil {
+		return forwardSetupError{fmt.Errorf("local port %d is not available: %w", localPort, err)}
+	}
+	listener.Close()
+	url := client.CoreV1().RESTClient().Post().Resource("pods").Namespace(namespace).Name(pod).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", url)
+
+	stop := make(chan struct{})
+	podCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		defer close(stop)
+		ticker := time.NewTicker(forwardPodCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-podCtx.Done():
+				return
+			case <-ticker.C:
+				current, err := client.CoreV1().Pods(namespace).Get(podCtx, pod, metav1.GetOptions{})
+				if err != nil || current.DeletionTimestamp != nil || current.Status.Phase != corev1.PodRunning {
+					return
+				}
+			}
+		}
+	}()
+
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"localhost"}, []string{fmt.Sprintf("%d:%d", localPort, remotePort)}, stop, nil, io.Discard, out)
+	if err != nil {
+		return forwardSetupError{err}
+	}
+	return forwarder.ForwardPorts()
+}
+
+// resolveForwardTarget returns a running pod of the target and the port of the container.
+func resolveForwardTarget(ctx context.Context, client kubernetes.Interface, target ForwardTarget) (string, int, error) {
+	pods := client.CoreV1().Pods(target.Namespace)
+
+	if target.Service == "" {
+		if !strings.ContainsAny(target.Pod, "=!") {
+			pod, err := pods.Get(ctx, target.Pod, metav1.GetOptions{})
+			if err != nil {
+				return "", 0, err
+			}
+			if pod.Status.Phase != corev1.PodRunning {
+				return "", 0, fmt.Errorf("pod %s is %s", pod.Name, pod.Status.Phase)
+			}
+			return pod.Name, target.RemotePort, nil
+		}
+		pod, err := readyPod(ctx, client, target.Namespace, target.Pod)
+		if err != nil {
+			return "", 0, err
+		}
+		return pod.Name, target.RemotePort, nil
+	}
+
+	service, err := client.CoreV1().Services(target.Namespace).Get(ctx, target.Service, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, err
+	}
+	var servicePort *corev1.ServicePort
+	for i, port := range service.Spec.Ports {
+		if int(port.Port) == target.RemotePort {
+			servicePort = &service.Spec.Ports[i]
+			break
+		}
+	}
+	if servicePort == nil {
+		return "", 0, fmt.Errorf("service %s has no port %d", service.Name, target.RemotePort)
+	}
+	if len(service.Spec.Selector) == 0 {
+		return "", 0, fmt.Errorf("service %s has no selector, forward to one of its pods instead", service.Name)
+	}
+
+	pod, err := readyPod(ctx, client, target.Namespace, labels.SelectorFromSet(service.Spec.Selector).String())
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := containerPort(pod, servicePort)
+	return pod.Name, port, err
+}
+
+// readyPod returns a running pod matching the selector, preferring ready pods.
+func readyPod(ctx context.Context, client kubernetes.Interface, namespace string, selector string) (*corev1.Pod, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	var running *corev1.Pod
+	for i, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		status := GetPodStatus(&pods.Items[i])
+		if status.Ready == status.Total {
+			return &pods.Items[i], nil
+		}
+		if running == nil {
+			running = &pods.Items[i]
+		}
+	}
+	if running == nil {
+		return nil, fmt.Errorf("no running pod matches %s", selector)
+	}
+	return running, nil
+}
+
+// containerPort resolves the target port of the service port in the pod.
+func containerPort(pod *corev1.Pod, servicePort *corev1.ServicePort) (int, error) {
+	switch {
+	case servicePort.TargetPort.Type == intstr.Int && servicePort.TargetPort.IntVal != 0:
+		return int(servicePort.TargetPort.IntVal), nil
+	case servicePort.TargetPort.Type == intstr.String && servicePort.TargetPort.StrVal != "":
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				if port.Name == servicePort.TargetPort.StrVal {
+					return int(port.ContainerPort), nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("pod %s has no port named %s", pod.Name, servicePort.TargetPort.StrVal)
+	}
+	return int(servicePort.Port), nil
+}
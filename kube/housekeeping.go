@@ -0,0 +1,244 @@
+package kube
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Strategies to handle imported entries that have the name of an existing, different entry
+const (
+	ConflictSkip      string = "skip"
+	ConflictOverwrite string = "overwrite"
+	ConflictRename    string = "rename"
+)
+
+func (kc *KubeConfig) RenameContext(name string, newName string) error {
+	context, ok := kc.Config.Contexts[name]
+	if !ok {
+		return fmt.Errorf("the context %s does not exist in the config", name)
+	}
+	if _, ok := kc.Config.Contexts[newName]; ok {
+		return fmt.Errorf("the context %s already exists in the config", newName)
+	}
+
+	// the renamed context stays in the file that defines it
+	kc.Config.Contexts[newName] = context
+	delete(kc.Config.Contexts, name)
+	if kc.Config.CurrentContext == name {
+		kc.Config.CurrentContext = newName
+	}
+	return nil
+}
+
+// Import merges the clusters, users and contexts of another config. Identical entries
+// are skipped, entries with the name of a different entry are handled with onConflict.
+// The returned messages describe the conflicts.
+func (kc *KubeConfig) Import(other *api.Config, onConflict string) []string {
+	var messages []string
+	report := func(kind string, name string, target string, write bool) {
+		switch {
+		case target != name:
+			messages = append(messages, fmt.Sprintf("%s %s already exists, imported as %s", kind, name, target))
+		case write:
+			messages = append(messages, fmt.Sprintf("%s %s already exists, overwritten", kind, name))
+		default:
+			messages = append(messages, fmt.Sprintf("%s %s already exists, skipped", kind, name))
+		}
+	}
+
+	clusterNames := map[string]string{}
+	for name, cluster := range other.Clusters {
+		cluster = cluster.DeepCopy()
+		existing, exists := kc.Config.Clusters[name]
+		if exists {
+			cluster.LocationOfOrigin = existing.LocationOfOrigin
+			if reflect.DeepEqual(existing, cluster) {
+				continue
+			}
+		} else {
+			cluster.LocationOfOrigin = ""
+		}
+		target, write := importName(name, onConflict, exists, func(n string) bool { _, ok := kc.Config.Clusters[n]; return ok })
+		if exists {
+			report("cluster", name, target, write)
+		}
+		if target != name {
+			cluster.LocationOfOrigin = ""
+		}
+		clusterNames[name] = target
+		if write {
+			kc.Config.Clusters[target] = cluster
+		}
+	}
+
+	userNames := map[string]string{}
+	for name, user := range other.AuthInfos {
+		user = user.DeepCopy()
+		existing, exists := kc.Config.AuthInfos[name]
+		if exists {
+			user.LocationOfOrigin = existing.LocationOfOrigin
+			if reflect.DeepEqual(existing, user) {
+				continue
+			}
+		} else {
+			user.LocationOfOrigin = ""
+		}
+		target, write := importName(name, onConflict, exists, func(n string) bool { _, ok := kc.Config.AuthInfos[n]; return ok })
+		if exists {
+			report("user", name, target, write)
+		}
+		if target != name {
+			user.LocationOfOrigin = ""
+		}
+		userNames[name] = target
+		if write {
+			kc.Config.AuthInfos[target] = user
+		}
+	}
+
+	for name, context := range other.Contexts {
+		context = context.DeepCopy()
+		if target, ok := clusterNames[context.Cluster]; ok {
+			context.Cluster = target
+		}
+		if target, ok := userNames[context.AuthInfo]; ok {
+			context.AuthInfo = target
+		}
+		existing, exists := kc.Config.Contexts[name]
+		if exists {
+			context.LocationOfOrigin = existing.LocationOfOrigin
+			if reflect.DeepEqual(existing, context) {
+				continue
+			}
+		} else {
+			context.LocationOfOrigin = ""
+		}
+		target, write := importName(name, onConflict, exists, func(n string) bool { _, ok := kc.Config.Contexts[n]; return ok })
+		if exists {
+			report("context", name, target, write)
+		}
+		if target != name {
+			context.LocationOfOrigin = ""
+		}
+		if write {
+			kc.Config.Contexts[target] = context
+		}
+	}
+	return messages
+}
+
+// importName returns the name an imported entry is stored under and whether it is written.
+func importName(name string, onConflict string, exists bool, inUse func(string) bool) (string, bool) {
+	if !exists {
+		return name, true
+	}
+	switch onConflict {
+	case ConflictOverwrite:
+		return name, true
+	case ConflictRename:
+		for i := 2; ; i++ {
+			if candidate := fmt.Sprintf("%s-%d", name, i); !inUse(candidate) {
+				return candidate, true
+			}
+		}
+	}
+	return name, false
+}
+
+// Export returns a self-contained config with only the context, its cluster and user.
+// Certificates and keys referenced by path are embedded.
+func (kc *KubeConfig) Export(context string) (*api.Config, error) {
+	if _, ok := kc.Config.Contexts[context]; !ok {
+		return nil, fmt.Errorf("the context %s does not exist in the config", context)
+	}
+
+	config := kc.Config.DeepCopy()
+	config.CurrentContext = context
+	if err := api.MinifyConfig(config); err != nil {
+		return nil, err
+	}
+	if err := clientcmd.ResolveLocalPaths(config); err != nil {
+		return nil, err
+	}
+	if err := api.FlattenConfig(config); err != nil {
+		return nil, err
+	}
+
+	for _, cluster := range config.Clusters {
+		cluster.LocationOfOrigin = ""
+	}
+	for _, user := range config.AuthInfos {
+		user.LocationOfOrigin = ""
+	}
+	for _, c := range config.Contexts {
+		c.LocationOfOrigin = ""
+	}
+	return config, nil
+}
+
+// Backup copies every file of the kube config to <file>.<timestamp>.bak and returns the copies.
+func (kc *KubeConfig) Backup() ([]string, error) {
+	var backups []string
+	timestamp := time.Now().Format("20060102-150405")
+
+	for _, path := range kc.PathOptions.GetLoadingPrecedence() {
+		if !fileExists(path) {
+			continue
+		}
+		backup := fmt.Sprintf("%s.%s.bak", path, timestamp)
+		if err := copyFile(path, backup); err != nil {
+			return backups, err
+		}
+		backups = append(backups, backup)
+	}
+	return backups, nil
+}
+
+// Ping checks if the API server of the context answers within the timeout. The server is
+// asked for its version without credentials, so an expired session or a credential plugin
+// that prompts for MFA does not make it unreachable, and a server that rejects anonymous
+// requests still answers.
+func (kc *KubeConfig) Ping(context string, timeout time.Duration) error {
+	config, err := kc.ClientConfig(context).ClientConfig()
+	if err != nil {
+		return err
+	}
+	config = rest.AnonymousClientConfig(config)
+	config.Timeout = timeout
+
+	client, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return err
+	}
+	_, err = client.ServerVersion()
+	if apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err) {
+		return nil
+	}
+	return err
+}
+
+func copyFile(source string, destination string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(destination, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
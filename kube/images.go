@@ -0,0 +1,160 @@
+package kube
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ImageUsage is a workload that runs an image, or whose spec references it
+type ImageUsage struct {
+	Namespace string
+	Kind      string
+	Name      string
+	// Replicas is the number of pods running the image
+	Replicas int
+}
+
+// FindImage returns the workloads in all namespaces that run the image with the digest, found by
+// the image ID of their pods, and the workloads whose spec references the repository at the tag or digest.
+func FindImage(ctx context.Context, client kubernetes.Interface, repositoryUri string, tag string, digest string) ([]ImageUsage, error) {
+	images, err := FindRepositoryImages(ctx, client, repositoryUri)
+	if err != nil {
+		return nil, err
+	}
+	var result []ImageUsage
+	for _, reference := range []string{digest, tag} {
+		if reference == "" {
+			continue
+		}
+		for _, u := range images[reference] {
+			i := slices.IndexFunc(result, func(r ImageUsage) bool {
+				return r.Namespace == u.Namespace && r.Kind == u.Kind && r.Name == u.Name
+			})
+			if i < 0 {
+				result = append(result, u)
+			} else {
+				result[i].Replicas += u.Replicas
+			}
+		}
+	}
+	sortUsages(result)
+	return result, nil
+}
+
+// ImageReference returns the tag or digest of an image reference of the repository, e.g. 1.4.2 of
+// repo:1.4.2 and the digest of repo:1.4.2@sha256:... The image may be an image ID with a scheme,
+// e.g. docker-pullable://repo@sha256:... ok is false when the image is not of the repository.
+func ImageReference(image string, repositoryUri string) (reference string, ok bool) {
+	if _, after, found := strings.Cut(image, "://"); found {
+		image = after
+	}
+	rest, found := strings.CutPrefix(image, repositoryUri)
+	if !found || rest == "" || (rest[0] != ':' && rest[0] != '@') {
+		return "", false
+	}
+	if at := strings.LastIndex(rest, "@"); at >= 0 {
+		return rest[at+1:], true
+	}
+	return rest[1:], true
+}
+
+// FindRepositoryImages returns the workloads in all namespaces that run or reference an image of the
+// repository, by the digest of the image ID of their pods and by the tag or digest of their spec.
+func FindRepositoryImages(ctx context.Context, client kubernetes.Interface, repositoryUri string) (map[string][]ImageUsage, error) {
+	usages := map[string]map[string]*ImageUsage{}
+	usage := func(reference string, namespace string, kind string, name string) *ImageUsage {
+		key := namespace + "/" + kind + "/" + name
+		if usages[reference] == nil {
+			usages[reference] = map[string]*ImageUsage{}
+		}
+		if usages[reference][key] == nil {
+			usages[reference][key] = &ImageUsage{Namespace: namespace, Kind: kind, Name: name}
+		}
+		return usages[reference][key]
+	}
+	fromSpec := func(spec *corev1.PodSpec, namespace string, kind string, name string) {
+		for _, container := range slices.Concat(spec.Containers, spec.InitContainers) {
+			if reference, ok := ImageReference(container.Image, repositoryUri); ok {
+				usage(reference, namespace, kind, name)
+			}
+		}
+	}
+
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		kind, name := podWorkload(&pod)
+		digests := map[string]bool{}
+		for _, container := range slices.Concat(pod.Status.ContainerStatuses, pod.Status.InitContainerStatuses) {
+			if digest, ok := ImageReference(container.ImageID, repositoryUri); ok && !digests[digest] {
+				digests[digest] = true
+				usage(digest, pod.Namespace, kind, name).Replicas++
+			}
+		}
+	}
+
+	deployments, err := client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		fromSpec(&deployment.Spec.Template.Spec, deployment.Namespace, "Deployment", deployment.Name)
+	}
+	statefulSets, err := client.AppsV1().StatefulSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		fromSpec(&statefulSet.Spec.Template.Spec, statefulSet.Namespace, "StatefulSet", statefulSet.Name)
+	}
+	daemonSets, err := client.AppsV1().DaemonSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, daemonSet := range daemonSets.Items {
+		fromSpec(&daemonSet.Spec.Template.Spec, daemonSet.Namespace, "DaemonSet", daemonSet.Name)
+	}
+
+	result := map[string][]ImageUsage{}
+	for reference, workloads := range usages {
+		for _, u := range workloads {
+			result[reference] = append(result[reference], *u)
+		}
+		sortUsages(result[reference])
+	}
+	return result, nil
+}
+
+func sortUsages(usages []ImageUsage) {
+	sort.Slice(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		return a.Namespace+"/"+a.Kind+"/"+a.Name < b.Namespace+"/"+b.Kind+"/"+b.Name
+	})
+}
+
+// podWorkload returns the kind and name of the workload that owns the pod, the
+// deployment for pods of a replica set.
+func podWorkload(pod *corev1.Pod) (string, string) {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Controller == nil || !*owner.Controller {
+			continue
+		}
+		if hash, ok := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; owner.Kind == "ReplicaSet" && ok {
+			return "Deployment", strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+		return owner.Kind, owner.Name
+	}
+	return "Pod", pod.Name
+}
@@ -0,0 +1,156 @@
+package kube
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// IsolatedConfigEnv points to the per-shell kube config of an isolated shell. The file
+// only holds the current context and namespace, and precedes the shared kube config
+// in KUBECONFIG so switching context in one shell does not affect the others.
+const IsolatedConfigEnv string = "DEVOOPS_KUBECONFIG"
+
+const isolatedConfigPrefix string = "kubeconfig-"
+
+func IsolatedConfigPath() string {
+	return os.Getenv(IsolatedConfigEnv)
+}
+
+// IsolatedConfigDir returns the runtime directory that holds the per-shell kube configs.
+func IsolatedConfigDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "devoops")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("devoops-%d", os.Getuid()))
+}
+
+// CleanIsolatedConfigs removes the per-shell kube configs of shells that are no
+// longer running, e.g. because they were killed before their exit trap ran.
+func CleanIsolatedConfigs() {
+	entries, err := os.ReadDir(IsolatedConfigDir())
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(strings.TrimPrefix(entry.Name(), isolatedConfigPrefix))
+		if err != nil || !strings.HasPrefix(entry.Name(), isolatedConfigPrefix) {
+			continue
+		}
+		if process, err := os.FindProcess(pid); err == nil && process.Signal(syscall.Signal(0)) == nil {
+			continue
+		}
+		os.Remove(filepath.Join(IsolatedConfigDir(), entry.Name()))
+	}
+}
+
+// IsolatedShellInit returns the shell code that isolates the shell evaluating it,
+// sharedPaths are the kube configs that are shared with the other shells. The per-shell
+// kube config is removed on exit after the EXIT trap the shell already has, which is read
+// from trap -p in bash and from trap in zsh.
+func IsolatedShellInit(sharedPaths []string) string {
+	return fmt.Sprintf(`export %[1]s="%[2]s/%[3]s$$"
+export KUBECONFIG="$%[1]s%[5]c%[4]s"
+__devoops_exit_trap=""
+__devoops_trap() { [ "$2" = EXIT ] && __devoops_exit_trap="$1"; return 0; }
+__devoops_traps="$(trap -p EXIT 2>/dev/null || trap)"
+eval "$(printf '%%s\n' "$__devoops_traps" | sed 's/^trap -- /__devoops_trap /')"
+trap "${__devoops_exit_trap:+$__devoops_exit_trap; }"'rm -f "$%[1]s"' EXIT
+unset -f __devoops_trap
+unset __devoops_exit_trap __devoops_traps
+`, IsolatedConfigEnv, IsolatedConfigDir(), isolatedConfigPrefix, strings.Join(sharedPaths, string(filepath.ListSeparator)), filepath.ListSeparator)
+}
+
+func (kc *KubeConfig) setIsolatedContext(context string) {
+	kc.Config.CurrentContext = context
+	kc.writeIsolated()
+}
+
+// writeIsolated writes the current context to the per-shell kube config. kubectl takes
+// the first definition of a context, so the contexts are copied to let the namespace of
+// this shell change without touching the shared kube config. Contexts the shell used
+// before keep their namespace, contexts that no longer exist are dropped.
+func (kc *KubeConfig) writeIsolated() {
+	previous, err := clientcmd.LoadFromFile(kc.IsolatedPath)
+	if err != nil && !os.IsNotExist(err) {
+		log.Fatalf("Failed to read %s: %v", kc.IsolatedPath, err)
+	}
+
+	isolated := api.NewConfig()
+	if previous != nil {
+		for name, context := range previous.Contexts {
+			if shared, ok := kc.Config.Contexts[name]; ok {
+				isolated.Contexts[name] = &api.Context{Cluster: shared.Cluster, AuthInfo: shared.AuthInfo, Namespace: context.Namespace}
+			}
+		}
+	}
+	if shared, ok := kc.Config.Contexts[kc.Config.CurrentContext]; ok {
+		isolated.CurrentContext = kc.Config.CurrentContext
+		if _, ok := isolated.Contexts[isolated.CurrentContext]; !ok {
+			isolated.Contexts[isolated.CurrentContext] = &api.Context{Cluster: shared.Cluster, AuthInfo: shared.AuthInfo, Namespace: shared.Namespace}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(kc.IsolatedPath), 0700); err != nil {
+		log.Fatalf("Failed to create %s: %v", filepath.Dir(kc.IsolatedPath), err)
+	}
+	if err := clientcmd.WriteToFile(*isolated, kc.IsolatedPath); err != nil {
+		log.Fatalf("Failed to write configuration to %s", kc.IsolatedPath)
+	}
+}
+
+// sharedConfigPath returns the first existing kube config of files that is shared with
+// the other shells, or the last one when none exists like kubectl does.
+func sharedConfigPath(files []string, isolatedPath string) string {
+	shared := slices.DeleteFunc(slices.Clone(files), func(file string) bool { return file == isolatedPath })
+	if len(shared) == 0 {
+		return isolatedPath
+	}
+	if i := slices.IndexFunc(shared, fileExists); i >= 0 {
+		return shared[i]
+	}
+	return shared[len(shared)-1]
+}
+
+// sharedConfigAccess is the ConfigAccess of an isolated shell. New entries and the current
+// context go to the first shared kube config instead of the per-shell kube config that is
+// removed when the shell exits, and the contexts copied to the per-shell kube config are
+// read from the shared kube config that defines them, so changes end up there.
+type sharedConfigAccess struct {
+	*clientcmd.PathOptions
+	isolatedPath string
+	sharedPath   string
+}
+
+func (a *sharedConfigAccess) GetDefaultFilename() string {
+	return a.sharedPath
+}
+
+func (a *sharedConfigAccess) GetStartingConfig() (*api.Config, error) {
+	config, err := a.PathOptions.GetStartingConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	loadingRules := *a.LoadingRules
+	loadingRules.Precedence = slices.DeleteFunc(a.GetLoadingPrecedence(), func(file string) bool { return file == a.isolatedPath })
+	shared, err := loadingRules.Load()
+	if err != nil {
+		return nil, err
+	}
+	for name, context := range config.Contexts {
+		if sharedContext, ok := shared.Contexts[name]; ok && context.LocationOfOrigin == a.isolatedPath {
+			config.Contexts[name] = sharedContext
+		}
+	}
+	return config, nil
+}
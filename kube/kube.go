@@ -1,10 +1,12 @@
 package kube
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
@@ -12,27 +14,46 @@ import (
 )
 
 type KubeConfig struct {
+	// ConfigPath is the file that receives the current context and new entries, the
+	// first existing file of KUBECONFIG like kubectl does
 	ConfigPath string
-	Config     *api.Config
+	// Config is the merged config of all files, every entry has its LocationOfOrigin
+	Config      *api.Config
+	PathOptions *clientcmd.PathOptions
+	// IsolatedPath is the per-shell kube config of the current shell, see IsolatedConfigPath
+	IsolatedPath string
+	// configAccess reads and writes the files, it keeps the entries of an isolated shell out
+	// of its per-shell kube config
+	configAccess clientcmd.ConfigAccess
 }
 
+// NewKubeConfig loads the kube config from configPath, or merges the files listed
+// in KUBECONFIG (default ~/.kube/config) when configPath is empty.
 func NewKubeConfig(configPath string) *KubeConfig {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		log.Fatal(err)
+	pathOptions := clientcmd.NewDefaultPathOptions()
+	if configPath != "" {
+		pathOptions.LoadingRules.ExplicitPath = configPath
 	}
 
-	kubeConfigPath := filepath.Join(home, ".kube", "config")
-	if configPath != "" {
-		kubeConfigPath = configPath
+	files := pathOptions.GetLoadingPrecedence()
+	if !slices.ContainsFunc(files, fileExists) {
+		log.Fatalf("The config could not be loaded from %s. Confirm the file exists.", strings.Join(files, string(filepath.ListSeparator)))
 	}
 
-	config, err := clientcmd.LoadFromFile(kubeConfigPath)
-	if err != nil {
-		log.Fatalf("The config could not be loaded from %s. Confirm the file exists.", kubeConfigPath)
+	kubeConfig := &KubeConfig{ConfigPath: pathOptions.GetDefaultFilename(), PathOptions: pathOptions, configAccess: pathOptions}
+	// the shell is only isolated as long as its kube config is part of KUBECONFIG
+	if isolatedPath := IsolatedConfigPath(); isolatedPath != "" && slices.Contains(files, isolatedPath) {
+		kubeConfig.IsolatedPath = isolatedPath
+		kubeConfig.ConfigPath = sharedConfigPath(files, isolatedPath)
+		kubeConfig.configAccess = &sharedConfigAccess{PathOptions: pathOptions, isolatedPath: isolatedPath, sharedPath: kubeConfig.ConfigPath}
 	}
 
-	return &KubeConfig{ConfigPath: kubeConfigPath, Config: config}
+	config, err := kubeConfig.configAccess.GetStartingConfig()
+	if err != nil {
+		log.Fatalf("The config could not be loaded from %s: %v", strings.Join(files, string(filepath.ListSeparator)), err)
+	}
+	kubeConfig.Config = config
+	return kubeConfig
 }
 
 func (kc *KubeConfig) GetContexts() []string {
@@ -50,6 +71,14 @@ func (kc *KubeConfig) GetContexts() []string {
 	return contexts
 }
 
+// GetContextFile returns the file that defines the context.
+func (kc *KubeConfig) GetContextFile(context string) string {
+	if c, ok := kc.Config.Contexts[context]; ok {
+		return c.LocationOfOrigin
+	}
+	return ""
+}
+
 func (kc *KubeConfig) GetCurrentContext() string {
 	if kc.Config.CurrentContext == "" {
 		log.Fatalf("CurrentConfig is not configured on config")
@@ -57,28 +86,93 @@ func (kc *KubeConfig) GetCurrentContext() string {
 	return kc.Config.CurrentContext
 }
 
+// SetCurrentContext writes the current context to the first file of KUBECONFIG,
+// or to the per-shell kube config when the shell is isolated.
 func (kc *KubeConfig) SetCurrentContext(context string) {
 	_, ok := kc.Config.Contexts[context]
 	if !ok {
 		log.Fatalf("The context %s does not exist in the config", context)
 	}
+	if kc.IsolatedPath != "" {
+		kc.setIsolatedContext(context)
+		return
+	}
 	kc.Config.CurrentContext = context
-	err := clientcmd.WriteToFile(*kc.Config, kc.ConfigPath)
-	if err != nil {
-		log.Fatalf("Failed to write configuration to %s", kc.ConfigPath)
+	kc.Write()
+}
+
+// Write saves the changes made to Config. Changed entries are written to the file
+// that defines them and new entries to ConfigPath. In an isolated shell the current
+// context is written to the per-shell kube config.
+func (kc *KubeConfig) Write() {
+	config := *kc.Config
+	isolated := kc.IsolatedPath != "" && fileExists(kc.IsolatedPath)
+	if isolated {
+		starting, err := kc.configAccess.GetStartingConfig()
+		if err != nil {
+			log.Fatalf("Failed to read configuration: %v", err)
+		}
+		config.CurrentContext = starting.CurrentContext
+	}
+	if err := clientcmd.ModifyConfig(kc.configAccess, config, true); err != nil {
+		log.Fatalf("Failed to write configuration to %s: %v", kc.ConfigPath, err)
+	}
+	if isolated {
+		kc.writeIsolated()
 	}
 }
 
+// ClientConfig returns the client config of the context, the current context when empty.
+func (kc *KubeConfig) ClientConfig(context string) clientcmd.ClientConfig {
+	// unlike PathOptions these rules resolve the relative paths of certificates
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kc.PathOptions.LoadingRules.ExplicitPath
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{CurrentContext: context})
+}
+
 func GetClient(kubeConfig *KubeConfig) *kubernetes.Clientset {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeConfig.ConfigPath)
+	client, err := GetClientForContext(kubeConfig, "")
 	if err != nil {
-		log.Fatalf("Failed to build config from %q", kubeConfig.ConfigPath)
+		log.Fatalf("Failed to initialize Kubernetes client: %v", err)
 	}
+	return client
+}
 
-	client, err := kubernetes.NewForConfig(config)
+// GetClientForContext returns a client for the context, the current context when empty.
+func GetClientForContext(kubeConfig *KubeConfig, context string) (*kubernetes.Clientset, error) {
+	config, err := kubeConfig.ClientConfig(context).ClientConfig()
 	if err != nil {
-		log.Fatalf("Failed to initialize Kubernetes client")
+		return nil, fmt.Errorf("failed to build config for context %q: %w", context, err)
 	}
+	return kubernetes.NewForConfig(config)
+}
 
-	return client
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// DeleteContext removes the context together with its cluster and user entries
+// when no other context references them.
+func (kc *KubeConfig) DeleteContext(name string) {
+	context, ok := kc.Config.Contexts[name]
+	if !ok {
+		return
+	}
+	delete(kc.Config.Contexts, name)
+	if kc.Config.CurrentContext == name {
+		kc.Config.CurrentContext = ""
+	}
+
+	clusterInUse, userInUse := false, false
+	for _, c := range kc.Config.Contexts {
+		clusterInUse = clusterInUse || c.Cluster == context.Cluster
+		userInUse = userInUse || c.AuthInfo == context.AuthInfo
+	}
+	if !clusterInUse {
+		delete(kc.Config.Clusters, context.Cluster)
+	}
+	if !userInUse {
+		delete(kc.Config.AuthInfos, context.AuthInfo)
+	}
 }
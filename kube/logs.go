@@ -0,0 +1,179 @@
+package kube
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+type LogOptions struct {
+	Namespace string
+	Selector  string
+	// Container limits the logs to the containers with this name, all containers when empty
+	Container string
+	// Since only returns logs newer than this duration, all logs when 0
+	Since  time.Duration
+	Follow bool
+}
+
+type LogLine struct {
+	Pod       string
+	Container string
+	Text      string
+}
+
+// logStreams tracks the log stream of every pod container
+type logStreams struct {
+	client  kubernetes.Interface
+	options LogOptions
+	lines   chan<- LogLine
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	// the running streams by pod UID and container, a recreated pod of a stateful set has the
+	// name of the pod it replaces but not its UID
+	running map[types.UID]map[string]*logStream
+	// when the stream of a pod container ended, a restarted container continues from there
+	ended map[string]time.Time
+}
+
+type logStream struct {
+	cancel context.CancelFunc
+}
+
+// StreamLogs writes the logs of all containers of the pods matching the selector to lines.
+// When following, pods that appear are picked up and pods that go away are no longer
+// followed until ctx is done. StreamLogs closes lines when it returns.
+func StreamLogs(ctx context.Context, client kubernetes.Interface, options LogOptions, lines chan<- LogLine) error {
+	streams := &logStreams{client: client, options: options, lines: lines, running: map[types.UID]map[string]*logStream{}, ended: map[string]time.Time{}}
+	defer close(lines)
+
+	if !options.Follow {
+		pods, err := client.CoreV1().Pods(options.Namespace).List(ctx, metav1.ListOptions{LabelSelector: options.Selector})
+		if err != nil {
+			return err
+		}
+		for i := range pods.Items {
+			streams.start(ctx, &pods.Items[i])
+		}
+		streams.wg.Wait()
+		return nil
+	}
+
+	// a failed watch stops the running streams, so the error is returned right away
+	ctx, cancel := context.WithCancel(ctx)
+	defer streams.wg.Wait()
+	defer cancel()
+	for ctx.Err() == nil {
+		// a watch without resource version starts with an ADDED event for every existing pod
+		watcher, err := client.CoreV1().Pods(options.Namespace).Watch(ctx, metav1.ListOptions{LabelSelector: options.Selector})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for event := range watcher.ResultChan() {
+			pod, ok := event.Object.(*corev1.Pod)
+			if !ok {
+				continue
+			}
+			if event.Type == watch.Deleted || pod.DeletionTimestamp != nil {
+				streams.stop(pod.UID)
+			} else {
+				streams.start(ctx, pod)
+			}
+		}
+		watcher.Stop()
+	}
+	return nil
+}
+
+// start streams the logs of the containers of the pod that have started and are not streamed yet.
+// A terminated container is streamed once, until it restarts.
+func (s *logStreams) start(ctx context.Context, pod *corev1.Pod) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if s.options.Container != "" && status.Name != s.options.Container {
+			continue
+		}
+		if status.State.Running == nil && status.State.Terminated == nil {
+			continue
+		}
+		if _, ok := s.running[pod.UID][status.Name]; ok {
+			continue
+		}
+		ended, streamed := s.ended[string(pod.UID)+"/"+status.Name]
+		if status.State.Terminated != nil && streamed {
+			continue
+		}
+		if s.running[pod.UID] == nil {
+			s.running[pod.UID] = map[string]*logStream{}
+		}
+
+		streamCtx, cancel := context.WithCancel(ctx)
+		stream := &logStream{cancel: cancel}
+		s.running[pod.UID][status.Name] = stream
+		s.wg.Add(1)
+		go s.stream(streamCtx, stream, pod, status.Name, ended)
+	}
+}
+
+func (s *logStreams) stop(uid types.UID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stream := range s.running[uid] {
+		stream.cancel()
+	}
+	delete(s.running, uid)
+}
+
+func (s *logStreams) stream(ctx context.Context, self *logStream, pod *corev1.Pod, container string, since time.Time) {
+	defer s.wg.Done()
+	defer func() {
+		// a restarted container is streamed again on the next update of the pod
+		s.mu.Lock()
+		if s.running[pod.UID][container] == self {
+			delete(s.running[pod.UID], container)
+		}
+		s.ended[string(pod.UID)+"/"+container] = time.Now()
+		s.mu.Unlock()
+	}()
+
+	options := &corev1.PodLogOptions{Container: container, Follow: s.options.Follow}
+	if !since.IsZero() {
+		options.SinceTime = &metav1.Time{Time: since}
+	} else if s.options.Since > 0 {
+		seconds := int64(s.options.Since.Seconds())
+		options.SinceSeconds = &seconds
+	}
+
+	stream, err := s.client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, options).Stream(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.lines <- LogLine{Pod: pod.Name, Container: container, Text: fmt.Sprintf("failed to stream logs: %v", err)}
+		}
+		return
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		select {
+		case s.lines <- LogLine{Pod: pod.Name, Container: container, Text: scanner.Text()}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package kube
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Problems in the order they are reported, the most broken first
+var Problems = []string{"CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull", "OOMKilled", "Evicted", "Pending"}
+
+type PodStatus struct {
+	Pod      *corev1.Pod
+	Ready    int
+	Total    int
+	Restarts int32
+	// Problem is one of Problems, or "" when the pod has none of them
+	Problem string
+	// LastTermination is the reason the last terminated container stopped
+	LastTermination string
+	Images          []string
+}
+
+func GetPodStatus(pod *corev1.Pod) *PodStatus {
+	status := &PodStatus{Pod: pod, Total: len(pod.Spec.Containers)}
+
+	for _, container := range pod.Spec.Containers {
+		status.Images = append(status.Images, container.Image)
+	}
+
+	for _, container := range pod.Status.ContainerStatuses {
+		if container.Ready {
+			status.Ready++
+		}
+	}
+
+	problems := []string{}
+	for _, container := range append(slices.Clone(pod.Status.InitContainerStatuses), pod.Status.ContainerStatuses...) {
+		status.Restarts += container.RestartCount
+
+		if waiting := container.State.Waiting; waiting != nil {
+			problems = append(problems, waiting.Reason)
+		}
+		if terminated := container.State.Terminated; terminated != nil {
+			problems = append(problems, terminated.Reason)
+			status.LastTermination = terminated.Reason
+		}
+		if terminated := container.LastTerminationState.Terminated; terminated != nil {
+			problems = append(problems, terminated.Reason)
+			if status.LastTermination == "" {
+				status.LastTermination = terminated.Reason
+			}
+		}
+	}
+	problems = append(problems, pod.Status.Reason, string(pod.Status.Phase))
+
+	for _, problem := range Problems {
+		if slices.Contains(problems, problem) {
+			status.Problem = problem
+			break
+		}
+	}
+	return status
+}
+
+// Severity ranks the pod, a lower severity is more broken.
+func (s *PodStatus) Severity() int {
+	if s.Problem != "" {
+		return slices.Index(Problems, s.Problem)
+	}
+	if s.Ready < s.Total && s.Pod.Status.Phase != corev1.PodSucceeded {
+		return len(Problems)
+	}
+	return len(Problems) + 1
+}
+
+// ImageTags returns the tags of the images, or the shortened digest for images pinned by digest.
+func (s *PodStatus) ImageTags() []string {
+	var tags []string
+	for _, image := range s.Images {
+		tags = append(tags, ImageTag(image))
+	}
+	return tags
+}
+
+// ImageTag returns the tag or shortened digest of an image reference, "latest" when it has neither.
+func ImageTag(image string) string {
+	if _, digest, ok := strings.Cut(image, "@"); ok {
+		if _, hash, ok := strings.Cut(digest, ":"); ok && len(hash) > 12 {
+			return "@" + hash[:12]
+		}
+		return "@" + digest
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if _, tag, ok := strings.Cut(name, ":"); ok {
+		return tag
+	}
+	return "latest"
+}
+
+// ListPodStatus lists the pods of the namespace, all namespaces when empty, sorted
+// with the most broken pods first.
+func ListPodStatus(client kubernetes.Interface, namespace string, selector string) ([]*PodStatus, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*PodStatus
+	for i := range pods.Items {
+		statuses = append(statuses, GetPodStatus(&pods.Items[i]))
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if a.Severity() != b.Severity() {
+			return a.Severity() < b.Severity()
+		}
+		if a.Restarts != b.Restarts {
+			return a.Restarts > b.Restarts
+		}
+		return a.Pod.Namespace+"/"+a.Pod.Name < b.Pod.Namespace+"/"+b.Pod.Name
+	})
+	return statuses, nil
+}
+
+// ListPodEvents returns the events of the pods in the namespace by pod UID, the most recent last.
+func ListPodEvents(client kubernetes.Interface, namespace string) (map[string][]corev1.Event, error) {
+	events, err := client.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{FieldSelector: "involvedObject.kind=Pod"})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(events.Items, func(i, j int) bool {
+		return EventTime(&events.Items[i]).Before(EventTime(&events.Items[j]))
+	})
+	podEvents := map[string][]corev1.Event{}
+	for _, event := range events.Items {
+		uid := string(event.InvolvedObject.UID)
+		podEvents[uid] = append(podEvents[uid], event)
+	}
+	return podEvents, nil
+}
+
+// EventTime returns when the event last happened.
+func EventTime(event *corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
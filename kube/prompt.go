@@ -0,0 +1,73 @@
+package kube
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// promptConfig is the part of a kube config a shell prompt needs
+type promptConfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+type CurrentContext struct {
+	Context   string `json:"context"`
+	Cluster   string `json:"cluster"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace"`
+	Isolated  bool   `json:"isolated"`
+}
+
+// ReadCurrentContext is a fast alternative to NewKubeConfig for shell prompts. It only
+// decodes the current context and the contexts of the kube config files, without
+// validating the config. It returns nil when no current context is set.
+func ReadCurrentContext() *CurrentContext {
+	var configs []promptConfig
+	var paths []string
+	for _, path := range clientcmd.NewDefaultPathOptions().GetLoadingPrecedence() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var config promptConfig
+		if err := yaml.Unmarshal(content, &config); err != nil {
+			continue
+		}
+		configs = append(configs, config)
+		paths = append(paths, path)
+	}
+
+	// like kubectl, the first file that sets the current context or defines a context wins
+	var current *CurrentContext
+	for i, config := range configs {
+		if config.CurrentContext != "" {
+			current = &CurrentContext{Context: config.CurrentContext, Isolated: paths[i] == IsolatedConfigPath()}
+			break
+		}
+	}
+	if current == nil {
+		return nil
+	}
+
+	for _, config := range configs {
+		for _, context := range config.Contexts {
+			if context.Name == current.Context {
+				current.Cluster = context.Context.Cluster
+				current.User = context.Context.User
+				current.Namespace = context.Context.Namespace
+				return current
+			}
+		}
+	}
+	return current
+}
@@ -0,0 +1,147 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// how often the status of a rollout is checked
+const rolloutPollInterval = 2 * time.Second
+
+// SetImage sets the image of the container of the deployment and returns the previous image.
+// The container may be empty when the deployment has a single container. Like kubectl set image,
+// only the image of the container is patched, so concurrent changes to the deployment are kept.
+func SetImage(client kubernetes.Interface, namespace string, name string, container string, image string) (string, error) {
+	deployments := client.AppsV1().Deployments(namespace)
+	deployment, err := deployments.Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	index, err := containerIndex(deployment, container)
+	if err != nil {
+		return "", err
+	}
+	previous := deployment.Spec.Template.Spec.Containers[index].Image
+	if previous == image {
+		return previous, nil
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+			"containers": []map[string]string{{"name": deployment.Spec.Template.Spec.Containers[index].Name, "image": image}},
+		}}},
+	})
+	if err != nil {
+		return "", err
+	}
+	if _, err := deployments.Patch(context.Background(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return "", err
+	}
+	return previous, nil
+}
+
+func containerIndex(deployment *appsv1.Deployment, container string) (int, error) {
+	containers := deployment.Spec.Template.Spec.Containers
+	var names []string
+	for i, c := range containers {
+		if c.Name == container || (container == "" && len(containers) == 1) {
+			return i, nil
+		}
+		names = append(names, c.Name)
+	}
+	if container == "" {
+		return 0, fmt.Errorf("deployment %s has several containers, choose one of %s", deployment.Name, strings.Join(names, ", "))
+	}
+	return 0, fmt.Errorf("deployment %s has no container %s, choose one of %s", deployment.Name, container, strings.Join(names, ", "))
+}
+
+// RolloutStatus returns a description of the rollout of the deployment, whether it is done,
+// and an error when the rollout exceeded its progress deadline. It follows kubectl rollout status.
+func RolloutStatus(deployment *appsv1.Deployment) (string, bool, error) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return "Waiting for the rollout to start", false, nil
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return "", false, fmt.Errorf("deployment %s exceeded its progress deadline", deployment.Name)
+		}
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	switch {
+	case status.UpdatedReplicas < replicas:
+		return fmt.Sprintf("%d of %d updated replicas", status.UpdatedReplicas, replicas), false, nil
+	case status.Replicas > status.UpdatedReplicas:
+		return fmt.Sprintf("%d old replicas are pending termination", status.Replicas-status.UpdatedReplicas), false, nil
+	case status.AvailableReplicas < status.UpdatedReplicas:
+		return fmt.Sprintf("%d of %d updated replicas are available", status.AvailableReplicas, status.UpdatedReplicas), false, nil
+	}
+	return fmt.Sprintf("%d of %d updated replicas are available", status.AvailableReplicas, replicas), true, nil
+}
+
+// WaitForRollout waits until the rollout of the deployment is done, calling progress every
+// time the status changes. It fails when the rollout exceeds its progress deadline or ctx is done.
+func WaitForRollout(ctx context.Context, client kubernetes.Interface, namespace string, name string, progress func(string)) error {
+	var last string
+	for {
+		deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		status, done, err := RolloutStatus(deployment)
+		if err != nil {
+			return err
+		}
+		if status != last {
+			last = status
+			progress(status)
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-time.After(rolloutPollInterval):
+		case <-ctx.Done():
+			return fmt.Errorf("rollout of deployment %s did not finish: %s", name, last)
+		}
+	}
+}
+
+// FailingPods returns the pods of the deployment that have a problem or are not ready, the most broken first.
+func FailingPods(client kubernetes.Interface, namespace string, name string) ([]*PodStatus, error) {
+	deployment, err := client.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := ListPodStatus(client, namespace, selector.String())
+	if err != nil {
+		return nil, err
+	}
+	var failing []*PodStatus
+	for _, status := range statuses {
+		if status.Problem != "" || (status.Ready < status.Total && status.Pod.Status.Phase != corev1.PodSucceeded) {
+			failing = append(failing, status)
+		}
+	}
+	return failing, nil
+}
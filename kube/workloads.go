@@ -0,0 +1,245 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Workload is a Deployment, StatefulSet or CronJob with the parts of its spec that are compared by DiffWorkloads
+type Workload struct {
+	Namespace string
+	Kind      string
+	Name      string
+	// Replicas is nil for CronJobs
+	Replicas   *int32
+	Containers []corev1.Container
+	// ConfigMaps maps the names of the config maps the workload uses to their keys
+	ConfigMaps map[string][]string
+}
+
+func (w *Workload) Key() string {
+	return w.Namespace + "/" + w.Kind + "/" + w.Name
+}
+
+// WorkloadDiff is a workload that differs between two contexts
+type WorkloadDiff struct {
+	Key string
+	// Changes describe the differences as "<field>: <from> → <to>"
+	Changes []string
+}
+
+// ListWorkloads lists the Deployments, StatefulSets and CronJobs of the namespace, all namespaces when empty.
+func ListWorkloads(ctx context.Context, client kubernetes.Interface, namespace string) ([]Workload, error) {
+	configMaps, err := client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	configMapKeys := map[string][]string{}
+	for _, configMap := range configMaps.Items {
+		var keys []string
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		for key := range configMap.BinaryData {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		configMapKeys[configMap.Namespace+"/"+configMap.Name] = keys
+	}
+	workload := func(meta metav1.ObjectMeta, kind string, replicas *int32, spec *corev1.PodSpec) Workload {
+		w := Workload{Namespace: meta.Namespace, Kind: kind, Name: meta.Name, Replicas: replicas, Containers: spec.Containers, ConfigMaps: map[string][]string{}}
+		for _, name := range podConfigMaps(spec) {
+			w.ConfigMaps[name] = configMapKeys[meta.Namespace+"/"+name]
+		}
+		return w
+	}
+
+	var workloads []Workload
+	deployments, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		workloads = append(workloads, workload(deployment.ObjectMeta, "Deployment", deployment.Spec.Replicas, &deployment.Spec.Template.Spec))
+	}
+	statefulSets, err := client.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		workloads = append(workloads, workload(statefulSet.ObjectMeta, "StatefulSet", statefulSet.Spec.Replicas, &statefulSet.Spec.Template.Spec))
+	}
+	cronJobs, err := client.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, cronJob := range cronJobs.Items {
+		workloads = append(workloads, workload(cronJob.ObjectMeta, "CronJob", nil, &cronJob.Spec.JobTemplate.Spec.Template.Spec))
+	}
+	return workloads, nil
+}
+
+// podConfigMaps returns the names of the config maps used in env vars and volumes.
+func podConfigMaps(spec *corev1.PodSpec) []string {
+	var names []string
+	for _, container := range slices.Concat(spec.InitContainers, spec.Containers) {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				names = append(names, envFrom.ConfigMapRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
+				names = append(names, env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+		}
+	}
+	for _, volume := range spec.Volumes {
+		if volume.ConfigMap != nil {
+			names = append(names, volume.ConfigMap.Name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// DiffWorkloads compares the workloads of two contexts. It returns the keys of the workloads
+// that only exist in from, those that only exist in to, and the differences of the others.
+func DiffWorkloads(from []Workload, to []Workload) ([]string, []string, []WorkloadDiff) {
+	fromByKey := map[string]*Workload{}
+	for i := range from {
+		fromByKey[from[i].Key()] = &from[i]
+	}
+	toByKey := map[string]*Workload{}
+	for i := range to {
+		toByKey[to[i].Key()] = &to[i]
+	}
+
+	var onlyFrom, onlyTo []string
+	var diffs []WorkloadDiff
+	for key, f := range fromByKey {
+		t, ok := toByKey[key]
+		if !ok {
+			onlyFrom = append(onlyFrom, key)
+			continue
+		}
+		if changes := diffWorkload(f, t); len(changes) > 0 {
+			diffs = append(diffs, WorkloadDiff{Key: key, Changes: changes})
+		}
+	}
+	for key := range toByKey {
+		if _, ok := fromByKey[key]; !ok {
+			onlyTo = append(onlyTo, key)
+		}
+	}
+
+	sort.Strings(onlyFrom)
+	sort.Strings(onlyTo)
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Key < diffs[j].Key })
+	return onlyFrom, onlyTo, diffs
+}
+
+func diffWorkload(from *Workload, to *Workload) []string {
+	var changes []string
+	if from.Replicas != nil && to.Replicas != nil && *from.Replicas != *to.Replicas {
+		changes = append(changes, fmt.Sprintf("replicas: %d → %d", *from.Replicas, *to.Replicas))
+	}
+
+	for _, f := range from.Containers {
+		i := slices.IndexFunc(to.Containers, func(c corev1.Container) bool { return c.Name == f.Name })
+		if i < 0 {
+			changes = append(changes, fmt.Sprintf("container %s: only in from", f.Name))
+			continue
+		}
+		t := to.Containers[i]
+
+		if ImageTag(f.Image) != ImageTag(t.Image) {
+			changes = append(changes, fmt.Sprintf("image %s: %s → %s", f.Name, ImageTag(f.Image), ImageTag(t.Image)))
+		}
+		changes = append(changes, diffResources(f.Name, "requests", f.Resources.Requests, t.Resources.Requests)...)
+		changes = append(changes, diffResources(f.Name, "limits", f.Resources.Limits, t.Resources.Limits)...)
+
+		var fromEnv, toEnv []string
+		for _, env := range f.Env {
+			fromEnv = append(fromEnv, env.Name)
+		}
+		for _, env := range t.Env {
+			toEnv = append(toEnv, env.Name)
+		}
+		changes = append(changes, diffNames(fmt.Sprintf("env %s", f.Name), fromEnv, toEnv)...)
+	}
+	for _, t := range to.Containers {
+		if !slices.ContainsFunc(from.Containers, func(c corev1.Container) bool { return c.Name == t.Name }) {
+			changes = append(changes, fmt.Sprintf("container %s: only in to", t.Name))
+		}
+	}
+
+	var names []string
+	for name := range from.ConfigMaps {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if keys, ok := to.ConfigMaps[name]; ok {
+			changes = append(changes, diffNames("configmap "+name, from.ConfigMaps[name], keys)...)
+		}
+	}
+	return changes
+}
+
+func diffResources(container string, kind string, from corev1.ResourceList, to corev1.ResourceList) []string {
+	var changes []string
+	var names []corev1.ResourceName
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range slices.Compact(names) {
+		fromQuantity, inFrom := from[name]
+		toQuantity, inTo := to[name]
+		// 1000Mi and 1048576000 are the same quantity in a different notation
+		if inFrom && inTo && fromQuantity.Cmp(toQuantity) == 0 {
+			continue
+		}
+		f, t := "-", "-"
+		if inFrom {
+			f = fromQuantity.String()
+		}
+		if inTo {
+			t = toQuantity.String()
+		}
+		if f != t {
+			changes = append(changes, fmt.Sprintf("%s %s %s: %s → %s", kind, container, name, f, t))
+		}
+	}
+	return changes
+}
+
+// diffNames reports the names that only exist on one side, "+name" when only in to and "-name" when only in from.
+func diffNames(field string, from []string, to []string) []string {
+	var added, removed []string
+	for _, name := range from {
+		if !slices.Contains(to, name) {
+			removed = append(removed, "-"+name)
+		}
+	}
+	for _, name := range to {
+		if !slices.Contains(from, name) {
+			added = append(added, "+"+name)
+		}
+	}
+	if len(added)+len(removed) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: %s", field, strings.Join(slices.Concat(removed, added), " "))}
+}